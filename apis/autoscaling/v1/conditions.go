@@ -0,0 +1,49 @@
+package v1
+
+import "errors"
+
+// Condition types of the Schedule and the ScheduledPodAutoscaler.
+const (
+	// ConditionReady indicates that the resource has been reconciled successfully.
+	ConditionReady = "Ready"
+	// ConditionActive indicates that scheduled scaling is taking place.
+	ConditionActive = "Active"
+	// ConditionDegraded indicates that the resource cannot be reconciled.
+	ConditionDegraded = "Degraded"
+	// ConditionDrifted indicates that the HPA has been changed manually from the spec applied by the controller.
+	ConditionDrifted = "Drifted"
+)
+
+// Condition reasons of the Schedule and the ScheduledPodAutoscaler.
+const (
+	ReasonReconciled       = "Reconciled"
+	ReasonScheduled        = "Scheduled"
+	ReasonNotScheduled     = "NotScheduled"
+	ReasonSuspended        = "Suspended"
+	ReasonCompleted        = "Completed"
+	ReasonInvalidTimeZone  = "InvalidTimeZone"
+	ReasonParseError       = "ParseError"
+	ReasonHPACreateFailed  = "HPACreateFailed"
+	ReasonHPAUpdateFailed  = "HPAUpdateFailed"
+	ReasonHPANotOwned      = "HPANotOwned"
+	ReasonHPAAdoptFailed   = "HPAAdoptFailed"
+	ReasonNoDrift          = "NoDrift"
+	ReasonDriftReverted    = "DriftReverted"
+	ReasonDriftRespected   = "DriftRespected"
+	ReasonDriftPaused      = "DriftPaused"
+	ReasonTargetNotFound   = "TargetNotFound"
+	ReasonScheduleActive   = "ScheduleActive"
+	ReasonNoActiveSchedule = "NoActiveSchedule"
+)
+
+// ErrInvalidTimeZone is returned when the time zone of the schedule cannot be loaded.
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// ConditionReason returns the condition reason corresponding to the error returned by the ScheduleSpec.
+func ConditionReason(err error) string {
+	if errors.Is(err, ErrInvalidTimeZone) {
+		return ReasonInvalidTimeZone
+	}
+
+	return ReasonParseError
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConditionReason(t *testing.T) {
+	now := time.Date(2018, 9, 2, 11, 00, 0, 0, time.UTC)
+
+	invalidTimeZone := ScheduleSpec{ScheduleType: Daily, StartTime: "10:00", EndTime: "19:00", TimeZone: "Invalid/TimeZone"}
+	_, timeZoneErr := invalidTimeZone.Contains(now)
+
+	invalidTime := ScheduleSpec{ScheduleType: Daily, StartTime: "invalid", EndTime: "19:00"}
+	_, parseErr := invalidTime.Contains(now)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "invalid time zone", err: timeZoneErr, expected: ReasonInvalidTimeZone},
+		{name: "parse error", err: parseErr, expected: ReasonParseError},
+		{name: "unknown error", err: errors.New("unknown"), expected: ReasonParseError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("error must not be nil")
+			}
+
+			if got := ConditionReason(tt.err); got != tt.expected {
+				t.Errorf("ConditionReason() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -19,9 +19,9 @@ var weekdays = map[string]time.Weekday{
 }
 
 func (s *ScheduleSpec) Contains(now time.Time) (bool, error) {
-	location, err := time.LoadLocation(s.TimeZone)
+	location, err := s.loadLocation()
 	if err != nil {
-		return false, fmt.Errorf("failed to load location %s: %w", s.TimeZone, err)
+		return false, err
 	}
 
 	now = now.In(location)
@@ -38,6 +38,15 @@ func (s *ScheduleSpec) Contains(now time.Time) (bool, error) {
 	}
 }
 
+func (s *ScheduleSpec) loadLocation() (*time.Location, error) {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to load location %s: %s", ErrInvalidTimeZone, s.TimeZone, err)
+	}
+
+	return location, nil
+}
+
 func (s *ScheduleSpec) containsDaily(now time.Time, location *time.Location) (bool, error) {
 	startTime, endTime, err := s.normalizeTime(now, location)
 	if err != nil {
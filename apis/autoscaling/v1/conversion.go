@@ -0,0 +1,477 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	autoscalingv2 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// AnnotationConversionData is the annotation that holds the fields which cannot be represented
+// in the converted version, so that the round trip conversion is lossless.
+// A v1 Schedule holds the v2 recurrence, and a v2 Schedule holds the v1 schedule times.
+const AnnotationConversionData = "autoscaling.d-kuro.github.io/conversion-data"
+
+// scheduleTimes is the fields of the v1 ScheduleSpec that correspond to the v2 Recurrence.
+type scheduleTimes struct {
+	ScheduleType   ScheduleType `json:"type"`
+	StartDayOfWeek string       `json:"startDayOfWeek,omitempty"`
+	EndDayOfWeek   string       `json:"endDayOfWeek,omitempty"`
+	StartTime      string       `json:"startTime,omitempty"`
+	EndTime        string       `json:"endTime,omitempty"`
+}
+
+// ConvertTo converts this Schedule to the Hub version (v2).
+func (s *Schedule) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*autoscalingv2.Schedule)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub: %T", dstRaw)
+	}
+
+	s.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	dst.Spec = autoscalingv2.ScheduleSpec{
+		ScaleTargetRef: s.Spec.ScaleTargetRef,
+		Suspend:        s.Spec.Suspend,
+		Description:    s.Spec.Description,
+		TimeZone:       s.Spec.TimeZone,
+		Mode:           autoscalingv2.ScheduleMode(s.Spec.Mode),
+		MinReplicas:    copyInt32(s.Spec.MinReplicas),
+		MaxReplicas:    copyInt32(s.Spec.MaxReplicas),
+	}
+
+	times := scheduleTimes{
+		ScheduleType:   s.Spec.ScheduleType,
+		StartDayOfWeek: s.Spec.StartDayOfWeek,
+		EndDayOfWeek:   s.Spec.EndDayOfWeek,
+		StartTime:      s.Spec.StartTime,
+		EndTime:        s.Spec.EndTime,
+	}
+
+	var recurrence autoscalingv2.Recurrence
+
+	restored, err := popConversionData(&dst.ObjectMeta, &recurrence)
+	if err != nil {
+		return err
+	}
+
+	if !restored || fromRecurrence(recurrence) != times {
+		recurrence = toRecurrence(times)
+	}
+
+	dst.Spec.Recurrence = recurrence
+
+	if fromRecurrence(recurrence) != times {
+		if err := setConversionData(&dst.ObjectMeta, times); err != nil {
+			return err
+		}
+	}
+
+	dst.Status = autoscalingv2.ScheduleStatus{
+		Conditions:         copyConditions(s.Status.Conditions),
+		ObservedGeneration: s.Status.ObservedGeneration,
+		SnapshotReplicas:   copyInt32(s.Status.SnapshotReplicas),
+		LastStartTime:      s.Status.LastStartTime.DeepCopy(),
+		LastEndTime:        s.Status.LastEndTime.DeepCopy(),
+		NextStartTime:      s.Status.NextStartTime.DeepCopy(),
+		NextEndTime:        s.Status.NextEndTime.DeepCopy(),
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v2) to this version.
+func (s *Schedule) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*autoscalingv2.Schedule)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub: %T", srcRaw)
+	}
+
+	src.ObjectMeta.DeepCopyInto(&s.ObjectMeta)
+
+	var times scheduleTimes
+
+	restored, err := popConversionData(&s.ObjectMeta, &times)
+	if err != nil {
+		return err
+	}
+
+	if !restored || !equality.Semantic.DeepEqual(toRecurrence(times), src.Spec.Recurrence) {
+		times = fromRecurrence(src.Spec.Recurrence)
+	}
+
+	if !equality.Semantic.DeepEqual(toRecurrence(times), src.Spec.Recurrence) {
+		if err := setConversionData(&s.ObjectMeta, src.Spec.Recurrence); err != nil {
+			return err
+		}
+	}
+
+	s.Spec = ScheduleSpec{
+		ScaleTargetRef: src.Spec.ScaleTargetRef,
+		Suspend:        src.Spec.Suspend,
+		Description:    src.Spec.Description,
+		TimeZone:       src.Spec.TimeZone,
+		Mode:           ScheduleMode(src.Spec.Mode),
+		MinReplicas:    copyInt32(src.Spec.MinReplicas),
+		MaxReplicas:    copyInt32(src.Spec.MaxReplicas),
+		ScheduleType:   times.ScheduleType,
+		StartDayOfWeek: times.StartDayOfWeek,
+		EndDayOfWeek:   times.EndDayOfWeek,
+		StartTime:      times.StartTime,
+		EndTime:        times.EndTime,
+	}
+
+	s.Status = ScheduleStatus{
+		LastTransitionTime: lastTransitionTime(src.Status.Conditions),
+		Condition:          scheduleCondition(src.Status.Conditions),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         copyConditions(src.Status.Conditions),
+		SnapshotReplicas:   copyInt32(src.Status.SnapshotReplicas),
+		LastStartTime:      src.Status.LastStartTime.DeepCopy(),
+		LastEndTime:        src.Status.LastEndTime.DeepCopy(),
+		NextStartTime:      src.Status.NextStartTime.DeepCopy(),
+		NextEndTime:        src.Status.NextEndTime.DeepCopy(),
+	}
+
+	return nil
+}
+
+// ConvertTo converts this ScheduledPodAutoscaler to the Hub version (v2).
+func (s *ScheduledPodAutoscaler) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*autoscalingv2.ScheduledPodAutoscaler)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub: %T", dstRaw)
+	}
+
+	s.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	dst.Spec = autoscalingv2.ScheduledPodAutoscalerSpec{
+		HorizontalPodAutoscalerSpec: *s.Spec.HorizontalPodAutoscalerSpec.DeepCopy(),
+		MinimumHoldDuration:         s.Spec.MinimumHoldDuration.DeepCopy(),
+		AdoptionPolicy:              autoscalingv2.AdoptionPolicy(s.Spec.AdoptionPolicy),
+		HPAName:                     s.Spec.HPAName,
+		HPALabels:                   copyStringMap(s.Spec.HPALabels),
+		HPAAnnotations:              copyStringMap(s.Spec.HPAAnnotations),
+		DeletionPolicy:              autoscalingv2.DeletionPolicy(s.Spec.DeletionPolicy),
+		DriftPolicy:                 autoscalingv2.DriftPolicy(s.Spec.DriftPolicy),
+	}
+
+	dst.Status = autoscalingv2.ScheduledPodAutoscalerStatus{
+		Conditions:         copyConditions(s.Status.Conditions),
+		ObservedGeneration: s.Status.ObservedGeneration,
+		CurrentMinReplicas: copyInt32(s.Status.CurrentMinReplicas),
+		CurrentMaxReplicas: s.Status.CurrentMaxReplicas,
+		ActiveSchedules:    append([]string(nil), s.Status.ActiveSchedules...),
+		EffectiveSchedule:  s.Status.EffectiveSchedule,
+		LastScaleTime:      s.Status.LastScaleTime.DeepCopy(),
+		HPAName:            s.Status.HPAName,
+	}
+
+	if s.Status.HPAAdoption != nil {
+		dst.Status.HPAAdoption = &autoscalingv2.HPAAdoptionStatus{
+			Result:  autoscalingv2.HPAAdoptionResult(s.Status.HPAAdoption.Result),
+			Time:    s.Status.HPAAdoption.Time,
+			Message: s.Status.HPAAdoption.Message,
+		}
+	}
+
+	if s.Status.DryRun != nil {
+		dst.Status.DryRun = &autoscalingv2.DryRunStatus{
+			MinReplicas: copyInt32(s.Status.DryRun.MinReplicas),
+			MaxReplicas: s.Status.DryRun.MaxReplicas,
+			Time:        s.Status.DryRun.Time,
+		}
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v2) to this version.
+func (s *ScheduledPodAutoscaler) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*autoscalingv2.ScheduledPodAutoscaler)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub: %T", srcRaw)
+	}
+
+	src.ObjectMeta.DeepCopyInto(&s.ObjectMeta)
+
+	s.Spec = ScheduledPodAutoscalerSpec{
+		HorizontalPodAutoscalerSpec: *src.Spec.HorizontalPodAutoscalerSpec.DeepCopy(),
+		MinimumHoldDuration:         src.Spec.MinimumHoldDuration.DeepCopy(),
+		AdoptionPolicy:              AdoptionPolicy(src.Spec.AdoptionPolicy),
+		HPAName:                     src.Spec.HPAName,
+		HPALabels:                   copyStringMap(src.Spec.HPALabels),
+		HPAAnnotations:              copyStringMap(src.Spec.HPAAnnotations),
+		DeletionPolicy:              DeletionPolicy(src.Spec.DeletionPolicy),
+		DriftPolicy:                 DriftPolicy(src.Spec.DriftPolicy),
+	}
+
+	s.Status = ScheduledPodAutoscalerStatus{
+		LastTransitionTime: lastTransitionTime(src.Status.Conditions),
+		Condition:          scheduledPodAutoscalerCondition(src.Status.Conditions),
+		Conditions:         copyConditions(src.Status.Conditions),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		CurrentMinReplicas: copyInt32(src.Status.CurrentMinReplicas),
+		CurrentMaxReplicas: src.Status.CurrentMaxReplicas,
+		ActiveSchedules:    append([]string(nil), src.Status.ActiveSchedules...),
+		EffectiveSchedule:  src.Status.EffectiveSchedule,
+		LastScaleTime:      src.Status.LastScaleTime.DeepCopy(),
+		HPAName:            src.Status.HPAName,
+	}
+
+	if src.Status.HPAAdoption != nil {
+		s.Status.HPAAdoption = &HPAAdoptionStatus{
+			Result:  HPAAdoptionResult(src.Status.HPAAdoption.Result),
+			Time:    src.Status.HPAAdoption.Time,
+			Message: src.Status.HPAAdoption.Message,
+		}
+	}
+
+	if src.Status.DryRun != nil {
+		s.Status.DryRun = &DryRunStatus{
+			MinReplicas: copyInt32(src.Status.DryRun.MinReplicas),
+			MaxReplicas: src.Status.DryRun.MaxReplicas,
+			Time:        src.Status.DryRun.Time,
+		}
+	}
+
+	return nil
+}
+
+// toRecurrence converts the v1 schedule times to the v2 recurrence.
+// The fields that cannot be parsed are left empty.
+func toRecurrence(times scheduleTimes) autoscalingv2.Recurrence {
+	recurrence := autoscalingv2.Recurrence{Type: autoscalingv2.RecurrenceType(times.ScheduleType)}
+
+	switch times.ScheduleType {
+	case Daily, Weekly:
+		recurrence.StartTime = toTimeOfDay(times.StartTime)
+		recurrence.EndTime = toTimeOfDay(times.EndTime)
+	case OneShot:
+		recurrence.StartDateTime = times.StartTime
+		recurrence.EndDateTime = times.EndTime
+	}
+
+	if times.ScheduleType != Weekly {
+		return recurrence
+	}
+
+	startWeekDay, startFound := weekdays[times.StartDayOfWeek]
+	endWeekDay, endFound := weekdays[times.EndDayOfWeek]
+
+	if startFound && endFound {
+		for day := startWeekDay; ; day = (day + 1) % 7 {
+			recurrence.DaysOfWeek = append(recurrence.DaysOfWeek, autoscalingv2.DaysOfWeek[day])
+
+			if day == endWeekDay {
+				break
+			}
+		}
+	}
+
+	return recurrence
+}
+
+// fromRecurrence converts the v2 recurrence to the v1 schedule times.
+// Days of week that are not consecutive cannot be represented in v1 and are left empty.
+// They are rejected by the validating webhook of the Schedule requested in v2.
+func fromRecurrence(recurrence autoscalingv2.Recurrence) scheduleTimes {
+	times := scheduleTimes{ScheduleType: ScheduleType(recurrence.Type)}
+
+	switch recurrence.Type {
+	case autoscalingv2.Daily, autoscalingv2.Weekly:
+		times.StartTime = fromTimeOfDay(recurrence.StartTime)
+		times.EndTime = fromTimeOfDay(recurrence.EndTime)
+	case autoscalingv2.OneShot:
+		times.StartTime = recurrence.StartDateTime
+		times.EndTime = recurrence.EndDateTime
+	}
+
+	if recurrence.Type == autoscalingv2.Weekly {
+		times.StartDayOfWeek, times.EndDayOfWeek = dayOfWeekRange(recurrence.DaysOfWeek)
+	}
+
+	return times
+}
+
+// dayOfWeekRange returns the first and last days of week if the days are consecutive.
+func dayOfWeekRange(days []autoscalingv2.DayOfWeek) (string, string) {
+	var set [7]bool
+
+	for _, day := range days {
+		weekday, found := weekdays[string(day)]
+		if !found || set[weekday] {
+			return "", ""
+		}
+
+		set[weekday] = true
+	}
+
+	if len(days) == 0 {
+		return "", ""
+	}
+
+	if len(days) == len(set) {
+		return string(autoscalingv2.Sunday), string(autoscalingv2.Saturday)
+	}
+
+	for start := range set {
+		if !set[start] || set[(start+6)%7] {
+			continue
+		}
+
+		end := (start + len(days) - 1) % 7
+
+		for i := 0; i < len(days); i++ {
+			if !set[(start+i)%7] {
+				return "", ""
+			}
+		}
+
+		return string(autoscalingv2.DaysOfWeek[start]), string(autoscalingv2.DaysOfWeek[end])
+	}
+
+	return "", ""
+}
+
+func toTimeOfDay(value string) *autoscalingv2.TimeOfDay {
+	t, err := time.Parse(clockLayout, value)
+	if err != nil {
+		return nil
+	}
+
+	return &autoscalingv2.TimeOfDay{Hour: int32(t.Hour()), Minute: int32(t.Minute())}
+}
+
+func fromTimeOfDay(t *autoscalingv2.TimeOfDay) string {
+	if t == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// setConversionData stores the value in the annotation as JSON.
+func setConversionData(obj *metav1.ObjectMeta, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversion data: %w", err)
+	}
+
+	if obj.Annotations == nil {
+		obj.Annotations = make(map[string]string)
+	}
+
+	obj.Annotations[AnnotationConversionData] = string(data)
+
+	return nil
+}
+
+// popConversionData restores the value from the annotation and removes the annotation.
+// It returns false if the annotation is not found.
+func popConversionData(obj *metav1.ObjectMeta, value interface{}) (bool, error) {
+	data, found := obj.Annotations[AnnotationConversionData]
+	if !found {
+		return false, nil
+	}
+
+	delete(obj.Annotations, AnnotationConversionData)
+
+	if len(obj.Annotations) == 0 {
+		obj.Annotations = nil
+	}
+
+	if err := json.Unmarshal([]byte(data), value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal conversion data: %w", err)
+	}
+
+	return true, nil
+}
+
+// scheduleCondition returns the v1 condition summarizing the standard conditions.
+func scheduleCondition(conditions []metav1.Condition) ScheduleConditionType {
+	if meta.IsStatusConditionTrue(conditions, ConditionDegraded) {
+		return ScheduleDegraded
+	}
+
+	active := meta.FindStatusCondition(conditions, ConditionActive)
+
+	switch {
+	case active == nil:
+		return ""
+	case active.Status == metav1.ConditionTrue:
+		return ScheduleProgressing
+	case active.Reason == ReasonSuspended:
+		return ScheduleSuspend
+	case active.Reason == ReasonCompleted:
+		return ScheduleCompleted
+	default:
+		return ScheduleAvailable
+	}
+}
+
+// scheduledPodAutoscalerCondition returns the v1 condition summarizing the standard conditions.
+func scheduledPodAutoscalerCondition(conditions []metav1.Condition) ScheduledPodAutoscalerConditionType {
+	switch {
+	case meta.IsStatusConditionTrue(conditions, ConditionDegraded):
+		return ScheduledPodAutoscalerDegraded
+	case meta.FindStatusCondition(conditions, ConditionReady) != nil:
+		return ScheduledPodAutoscalerAvailable
+	default:
+		return ""
+	}
+}
+
+// lastTransitionTime returns the latest transition time of the conditions.
+func lastTransitionTime(conditions []metav1.Condition) metav1.Time {
+	var last metav1.Time
+
+	for _, condition := range conditions {
+		if last.Before(&condition.LastTransitionTime) {
+			last = condition.LastTransitionTime
+		}
+	}
+
+	return last
+}
+
+func copyConditions(conditions []metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+
+	copied := make([]metav1.Condition, len(conditions))
+	for i := range conditions {
+		conditions[i].DeepCopyInto(&copied[i])
+	}
+
+	return copied
+}
+
+func copyInt32(value *int32) *int32 {
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+
+	return &copied
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+
+	return copied
+}
@@ -0,0 +1,228 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	autoscalingv2 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v2"
+	"github.com/google/go-cmp/cmp"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScheduleConvertTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        ScheduleSpec
+		expected    autoscalingv2.Recurrence
+		annotations map[string]string
+	}{
+		{
+			name: "daily",
+			spec: ScheduleSpec{ScheduleType: Daily, StartTime: "10:00", EndTime: "19:30"},
+			expected: autoscalingv2.Recurrence{
+				Type:      autoscalingv2.Daily,
+				StartTime: &autoscalingv2.TimeOfDay{Hour: 10},
+				EndTime:   &autoscalingv2.TimeOfDay{Hour: 19, Minute: 30},
+			},
+		},
+		{
+			name: "weekly across the weekend",
+			spec: ScheduleSpec{
+				ScheduleType: Weekly, StartDayOfWeek: "Friday", EndDayOfWeek: "Monday",
+				StartTime: "22:00", EndTime: "02:00",
+			},
+			expected: autoscalingv2.Recurrence{
+				Type:       autoscalingv2.Weekly,
+				DaysOfWeek: []autoscalingv2.DayOfWeek{"Friday", "Saturday", "Sunday", "Monday"},
+				StartTime:  &autoscalingv2.TimeOfDay{Hour: 22},
+				EndTime:    &autoscalingv2.TimeOfDay{Hour: 2},
+			},
+		},
+		{
+			name: "one shot",
+			spec: ScheduleSpec{ScheduleType: OneShot, StartTime: "2020-09-01T10:00", EndTime: "2020-09-01T12:00"},
+			expected: autoscalingv2.Recurrence{
+				Type:          autoscalingv2.OneShot,
+				StartDateTime: "2020-09-01T10:00",
+				EndDateTime:   "2020-09-01T12:00",
+			},
+		},
+		{
+			name: "invalid time",
+			spec: ScheduleSpec{ScheduleType: Daily, StartTime: "25:00", EndTime: "19:00"},
+			expected: autoscalingv2.Recurrence{
+				Type:    autoscalingv2.Daily,
+				EndTime: &autoscalingv2.TimeOfDay{Hour: 19},
+			},
+			annotations: map[string]string{
+				AnnotationConversionData: `{"type":"Daily","startTime":"25:00","endTime":"19:00"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			src := &Schedule{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: tt.spec}
+
+			var dst autoscalingv2.Schedule
+			if err := src.ConvertTo(&dst); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expected, dst.Spec.Recurrence); diff != "" {
+				t.Errorf("recurrence mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.annotations, dst.Annotations); diff != "" {
+				t.Errorf("annotations mismatch (-want +got):\n%s", diff)
+			}
+
+			var restored Schedule
+			if err := restored.ConvertFrom(&dst); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(src, &restored); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScheduleConvertFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence autoscalingv2.Recurrence
+		expected   scheduleTimes
+		lossy      bool
+	}{
+		{
+			name: "consecutive days of week",
+			recurrence: autoscalingv2.Recurrence{
+				Type:       autoscalingv2.Weekly,
+				DaysOfWeek: []autoscalingv2.DayOfWeek{"Saturday", "Sunday"},
+				StartTime:  &autoscalingv2.TimeOfDay{Hour: 9},
+				EndTime:    &autoscalingv2.TimeOfDay{Hour: 17},
+			},
+			expected: scheduleTimes{
+				ScheduleType: Weekly, StartDayOfWeek: "Saturday", EndDayOfWeek: "Sunday",
+				StartTime: "09:00", EndTime: "17:00",
+			},
+		},
+		{
+			name: "non-consecutive days of week",
+			recurrence: autoscalingv2.Recurrence{
+				Type:       autoscalingv2.Weekly,
+				DaysOfWeek: []autoscalingv2.DayOfWeek{"Monday", "Wednesday"},
+				StartTime:  &autoscalingv2.TimeOfDay{Hour: 9},
+				EndTime:    &autoscalingv2.TimeOfDay{Hour: 17},
+			},
+			expected: scheduleTimes{ScheduleType: Weekly, StartTime: "09:00", EndTime: "17:00"},
+			lossy:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			src := &autoscalingv2.Schedule{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       autoscalingv2.ScheduleSpec{Recurrence: tt.recurrence},
+			}
+
+			var dst Schedule
+			if err := dst.ConvertFrom(src); err != nil {
+				t.Fatal(err)
+			}
+
+			actual := scheduleTimes{
+				ScheduleType:   dst.Spec.ScheduleType,
+				StartDayOfWeek: dst.Spec.StartDayOfWeek,
+				EndDayOfWeek:   dst.Spec.EndDayOfWeek,
+				StartTime:      dst.Spec.StartTime,
+				EndTime:        dst.Spec.EndTime,
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("schedule times mismatch (-want +got):\n%s", diff)
+			}
+
+			if _, found := dst.Annotations[AnnotationConversionData]; found != tt.lossy {
+				t.Errorf("conversion data mismatch: want: %t, got: %t", tt.lossy, found)
+			}
+
+			var restored autoscalingv2.Schedule
+			if err := dst.ConvertTo(&restored); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(src, &restored); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScheduledPodAutoscalerConvert(t *testing.T) {
+	minReplicas := int32(2)
+	transitionTime := metav1.NewTime(time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC))
+
+	src := &autoscalingv2.ScheduledPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: autoscalingv2.ScheduledPodAutoscalerSpec{
+			HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    10,
+			},
+			MinimumHoldDuration: &metav1.Duration{Duration: time.Minute},
+			AdoptionPolicy:      autoscalingv2.AdoptionPolicyAdopt,
+			HPAName:             "nginx",
+			HPALabels:           map[string]string{"team": "web"},
+			HPAAnnotations:      map[string]string{"argocd.argoproj.io/compare-options": "IgnoreExtraneous"},
+			DeletionPolicy:      autoscalingv2.DeletionPolicyOrphanAtBaseline,
+			DriftPolicy:         autoscalingv2.DriftPolicyPause,
+		},
+		Status: autoscalingv2.ScheduledPodAutoscalerStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type: ConditionReady, Status: metav1.ConditionFalse,
+					Reason: ReasonHPAUpdateFailed, LastTransitionTime: transitionTime,
+				},
+				{Type: ConditionDegraded, Status: metav1.ConditionTrue, Reason: ReasonHPAUpdateFailed},
+			},
+			ActiveSchedules: []string{"test-1"},
+			HPAName:         "nginx",
+			HPAAdoption: &autoscalingv2.HPAAdoptionStatus{
+				Result: autoscalingv2.HPAAdopted, Time: transitionTime, Message: "adopted",
+			},
+			DryRun: &autoscalingv2.DryRunStatus{
+				MinReplicas: &minReplicas, MaxReplicas: 10, Time: transitionTime,
+			},
+		},
+	}
+
+	var dst ScheduledPodAutoscaler
+	if err := dst.ConvertFrom(src); err != nil {
+		t.Fatal(err)
+	}
+
+	if dst.Status.Condition != ScheduledPodAutoscalerDegraded {
+		t.Errorf("condition mismatch: want: %s, got: %s", ScheduledPodAutoscalerDegraded, dst.Status.Condition)
+	}
+
+	if !dst.Status.LastTransitionTime.Equal(&transitionTime) {
+		t.Errorf("lastTransitionTime mismatch: want: %s, got: %s", transitionTime, dst.Status.LastTransitionTime)
+	}
+
+	var restored autoscalingv2.ScheduledPodAutoscaler
+	if err := dst.ConvertTo(&restored); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(src, &restored); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
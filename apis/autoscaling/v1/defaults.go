@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"strings"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+)
+
+const (
+	// AnnotationDefaultTimeZone is the annotation of the namespace that specifies
+	// the time zone of the schedules in the namespace whose TimeZone is not specified.
+	AnnotationDefaultTimeZone = "autoscaling.d-kuro.github.io/default-time-zone"
+
+	// AnnotationAppliedDefaults is the annotation that records the fields defaulted by the webhook.
+	// It is a comma-separated list of the field paths.
+	AnnotationAppliedDefaults = "autoscaling.d-kuro.github.io/applied-defaults"
+)
+
+// defaultAPIVersions is the apiVersion of the well-known scale targets.
+var defaultAPIVersions = map[string]string{
+	"Deployment":            "apps/v1",
+	"StatefulSet":           "apps/v1",
+	"ReplicaSet":            "apps/v1",
+	"ReplicationController": "v1",
+}
+
+// SetDefaults sets the default values to the Schedule and returns the paths of the defaulted fields.
+// The timeZone is used when the TimeZone of the Schedule is not specified.
+func (s *Schedule) SetDefaults(timeZone string) []string {
+	var defaulted []string
+
+	if s.Spec.TimeZone == "" && timeZone != "" {
+		s.Spec.TimeZone = timeZone
+		defaulted = append(defaulted, "spec.timeZone")
+	}
+
+	if s.Spec.Mode == "" {
+		s.Spec.Mode = Scale
+		defaulted = append(defaulted, "spec.mode")
+	}
+
+	recordAppliedDefaults(&s.ObjectMeta.Annotations, defaulted)
+
+	return defaulted
+}
+
+// SetDefaults sets the default values to the ScheduledPodAutoscaler and returns the paths of the defaulted fields.
+func (s *ScheduledPodAutoscaler) SetDefaults() []string {
+	var defaulted []string
+
+	spec := &s.Spec.HorizontalPodAutoscalerSpec
+
+	if spec.MinReplicas == nil {
+		minReplicas := int32(1)
+		spec.MinReplicas = &minReplicas
+		defaulted = append(defaulted, "spec.horizontalPodAutoscalerSpec.minReplicas")
+	}
+
+	defaulted = append(defaulted, defaultScaleTargetRef(&spec.ScaleTargetRef,
+		"spec.horizontalPodAutoscalerSpec.scaleTargetRef")...)
+
+	recordAppliedDefaults(&s.ObjectMeta.Annotations, defaulted)
+
+	return defaulted
+}
+
+func defaultScaleTargetRef(ref *autoscalingv2beta2.CrossVersionObjectReference, path string) []string {
+	var defaulted []string
+
+	if ref.Kind == "" {
+		ref.Kind = "Deployment"
+		defaulted = append(defaulted, path+".kind")
+	}
+
+	if apiVersion, found := defaultAPIVersions[ref.Kind]; found && ref.APIVersion == "" {
+		ref.APIVersion = apiVersion
+		defaulted = append(defaulted, path+".apiVersion")
+	}
+
+	return defaulted
+}
+
+func recordAppliedDefaults(annotations *map[string]string, defaulted []string) {
+	if len(defaulted) == 0 {
+		return
+	}
+
+	if *annotations == nil {
+		*annotations = make(map[string]string)
+	}
+
+	(*annotations)[AnnotationAppliedDefaults] = strings.Join(defaulted, ",")
+}
@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+)
+
+func TestScheduleSetDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    Schedule
+		timeZone    string
+		expected    []string
+		expectedTZ  string
+		annotations map[string]string
+	}{
+		{
+			name:        "time zone and mode",
+			schedule:    Schedule{},
+			timeZone:    "Asia/Tokyo",
+			expected:    []string{"spec.timeZone", "spec.mode"},
+			expectedTZ:  "Asia/Tokyo",
+			annotations: map[string]string{AnnotationAppliedDefaults: "spec.timeZone,spec.mode"},
+		},
+		{
+			name:       "time zone is specified",
+			schedule:   Schedule{Spec: ScheduleSpec{TimeZone: "UTC", Mode: Freeze}},
+			timeZone:   "Asia/Tokyo",
+			expectedTZ: "UTC",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			defaulted := tt.schedule.SetDefaults(tt.timeZone)
+
+			if diff := cmp.Diff(tt.expected, defaulted); diff != "" {
+				t.Errorf("defaulted fields mismatch (-want +got):\n%s", diff)
+			}
+
+			if tt.schedule.Spec.TimeZone != tt.expectedTZ {
+				t.Errorf("timeZone mismatch: want: %s, got: %s", tt.expectedTZ, tt.schedule.Spec.TimeZone)
+			}
+
+			if diff := cmp.Diff(tt.annotations, tt.schedule.Annotations); diff != "" {
+				t.Errorf("annotations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScheduledPodAutoscalerSetDefaults(t *testing.T) {
+	three := int32(3)
+
+	tests := []struct {
+		name      string
+		spec      autoscalingv2beta2.HorizontalPodAutoscalerSpec
+		expected  []string
+		targetRef autoscalingv2beta2.CrossVersionObjectReference
+	}{
+		{
+			name: "kind and apiVersion",
+			spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				MinReplicas:    &three,
+			},
+			expected: []string{
+				"spec.horizontalPodAutoscalerSpec.scaleTargetRef.kind",
+				"spec.horizontalPodAutoscalerSpec.scaleTargetRef.apiVersion",
+			},
+			targetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"},
+		},
+		{
+			name: "apiVersion of well-known kind and minReplicas",
+			spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "StatefulSet", Name: "nginx"},
+			},
+			expected: []string{
+				"spec.horizontalPodAutoscalerSpec.minReplicas",
+				"spec.horizontalPodAutoscalerSpec.scaleTargetRef.apiVersion",
+			},
+			targetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "StatefulSet", Name: "nginx", APIVersion: "apps/v1"},
+		},
+		{
+			name: "custom resource",
+			spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Rollout", Name: "nginx"},
+				MinReplicas:    &three,
+			},
+			targetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Rollout", Name: "nginx"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			spa := ScheduledPodAutoscaler{Spec: ScheduledPodAutoscalerSpec{HorizontalPodAutoscalerSpec: tt.spec}}
+			defaulted := spa.SetDefaults()
+
+			if diff := cmp.Diff(tt.expected, defaulted); diff != "" {
+				t.Errorf("defaulted fields mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.targetRef, spa.Spec.HorizontalPodAutoscalerSpec.ScaleTargetRef); diff != "" {
+				t.Errorf("scaleTargetRef mismatch (-want +got):\n%s", diff)
+			}
+
+			if spa.Spec.HorizontalPodAutoscalerSpec.MinReplicas == nil {
+				t.Error("minReplicas must be set")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+)
+
+// overlapTimeLayout is the layout of the time in the overlap warnings.
+const overlapTimeLayout = "2006-01-02T15:04Z07:00"
+
+// OverlapWarnings compares the windows of the schedule with the windows of the sibling schedules
+// of the same ScheduledPodAutoscaler in the period [from, to), and returns the warnings
+// describing the overlapping, shadowed or contradictory windows.
+// The schedules that cannot be evaluated are ignored, since they are rejected by the validation.
+func (s *Schedule) OverlapWarnings(siblings []Schedule, from time.Time, to time.Time) []string {
+	if s.Spec.Suspend {
+		return nil
+	}
+
+	windows, err := s.Spec.Windows(from, to)
+	if err != nil || len(windows) == 0 {
+		return nil
+	}
+
+	var warnings []string
+
+	for i := range siblings {
+		sibling := &siblings[i]
+		if sibling.Name == s.Name || sibling.Spec.Suspend {
+			continue
+		}
+
+		siblingWindows, err := sibling.Spec.Windows(from, to)
+		if err != nil {
+			continue
+		}
+
+		overlaps, overlapped := overlapWindows(windows, siblingWindows)
+		if len(overlaps) == 0 {
+			continue
+		}
+
+		warnings = append(warnings, s.overlapWarning(sibling, windows, siblingWindows, overlaps, overlapped))
+	}
+
+	return warnings
+}
+
+func (s *Schedule) overlapWarning(sibling *Schedule, windows []ScheduleWindow, siblingWindows []ScheduleWindow,
+	overlaps []ScheduleWindow, overlapped time.Duration) string {
+	first := overlaps[0]
+	overlap := fmt.Sprintf("Schedule %s overlaps with Schedule %s %d time(s) from %s to %s",
+		s.Name, sibling.Name, len(overlaps),
+		first.Start.Format(overlapTimeLayout), first.End.Format(overlapTimeLayout))
+
+	switch {
+	case s.Spec.Mode == Freeze || sibling.Spec.Mode == Freeze:
+		return overlap + ": the snapshot replicas of the Freeze schedule are combined with the other replicas, " +
+			"so the replicas are not pinned"
+	case overlapped == totalDuration(windows) && dominates(&sibling.Spec, &s.Spec):
+		return overlap + fmt.Sprintf(": Schedule %s is shadowed by Schedule %s and never has any effect",
+			s.Name, sibling.Name)
+	case overlapped == totalDuration(siblingWindows) && dominates(&s.Spec, &sibling.Spec):
+		return overlap + fmt.Sprintf(": Schedule %s is shadowed by Schedule %s and never has any effect",
+			sibling.Name, s.Name)
+	case contradicts(&sibling.Spec, &s.Spec):
+		return overlap + ": the min/max replicas contradict each other, " +
+			"so the maximum of each is used and neither schedule is applied as defined"
+	default:
+		return overlap + ": the maximum of the min/max replicas is used"
+	}
+}
+
+// overlapWindows returns the intersections of the windows and the total duration of them.
+func overlapWindows(windows []ScheduleWindow, others []ScheduleWindow) ([]ScheduleWindow, time.Duration) {
+	var (
+		overlaps []ScheduleWindow
+		total    time.Duration
+	)
+
+	for _, window := range windows {
+		for _, other := range others {
+			start := window.Start
+			if other.Start.After(start) {
+				start = other.Start
+			}
+
+			end := window.End
+			if other.End.Before(end) {
+				end = other.End
+			}
+
+			if start.Before(end) {
+				overlaps = append(overlaps, ScheduleWindow{Start: start, End: end})
+				total += end.Sub(start)
+			}
+		}
+	}
+
+	return overlaps, total
+}
+
+func totalDuration(windows []ScheduleWindow) time.Duration {
+	var total time.Duration
+	for _, window := range windows {
+		total += window.End.Sub(window.Start)
+	}
+
+	return total
+}
+
+// dominates returns true if the replicas of the spec are always used instead of the replicas of the other.
+func dominates(spec *ScheduleSpec, other *ScheduleSpec) bool {
+	return dominatesReplicas(spec.MinReplicas, other.MinReplicas) &&
+		dominatesReplicas(spec.MaxReplicas, other.MaxReplicas)
+}
+
+func dominatesReplicas(replicas *int32, other *int32) bool {
+	if other == nil {
+		return true
+	}
+
+	return replicas != nil && *replicas >= *other
+}
+
+// contradicts returns true if one spec requests more min replicas but less max replicas than the other.
+func contradicts(spec *ScheduleSpec, other *ScheduleSpec) bool {
+	if spec.MinReplicas == nil || spec.MaxReplicas == nil || other.MinReplicas == nil || other.MaxReplicas == nil {
+		return false
+	}
+
+	return (*spec.MinReplicas > *other.MinReplicas && *spec.MaxReplicas < *other.MaxReplicas) ||
+		(*spec.MinReplicas < *other.MinReplicas && *spec.MaxReplicas > *other.MaxReplicas)
+}
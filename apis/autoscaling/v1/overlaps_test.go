@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScheduleOverlapWarnings(t *testing.T) {
+	two := int32(2)
+	five := int32(5)
+	ten := int32(10)
+	twenty := int32(20)
+
+	from := time.Date(2018, 9, 2, 0, 00, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 28)
+
+	newTestSchedule := func(name string, spec ScheduleSpec) Schedule {
+		return Schedule{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		siblings []Schedule
+		expected []string
+	}{
+		{
+			name: "no overlap",
+			schedule: newTestSchedule("new", ScheduleSpec{
+				ScheduleType: Daily, StartTime: "10:00", EndTime: "12:00", MinReplicas: &two,
+			}),
+			siblings: []Schedule{
+				newTestSchedule("old", ScheduleSpec{ScheduleType: Daily, StartTime: "12:00", EndTime: "13:00", MinReplicas: &five}),
+			},
+		},
+		{
+			name: "shadowed",
+			schedule: newTestSchedule("new", ScheduleSpec{
+				ScheduleType: Weekly, StartDayOfWeek: "Monday", EndDayOfWeek: "Monday",
+				StartTime: "10:00", EndTime: "12:00", MinReplicas: &two, MaxReplicas: &ten,
+			}),
+			siblings: []Schedule{
+				newTestSchedule("old", ScheduleSpec{
+					ScheduleType: Daily, StartTime: "09:00", EndTime: "18:00", MinReplicas: &five, MaxReplicas: &twenty,
+				}),
+			},
+			expected: []string{"Schedule new is shadowed by Schedule old"},
+		},
+		{
+			name: "shadows sibling",
+			schedule: newTestSchedule("new", ScheduleSpec{
+				ScheduleType: Daily, StartTime: "09:00", EndTime: "18:00", MinReplicas: &five, MaxReplicas: &twenty,
+			}),
+			siblings: []Schedule{
+				newTestSchedule("old", ScheduleSpec{
+					ScheduleType: OneShot, StartTime: "2018-09-03T10:00", EndTime: "2018-09-03T12:00", MinReplicas: &two,
+				}),
+			},
+			expected: []string{"Schedule old is shadowed by Schedule new"},
+		},
+		{
+			name: "contradictory",
+			schedule: newTestSchedule("new", ScheduleSpec{
+				ScheduleType: Daily, StartTime: "10:00", EndTime: "12:00", MinReplicas: &five, MaxReplicas: &ten,
+			}),
+			siblings: []Schedule{
+				newTestSchedule("old", ScheduleSpec{
+					ScheduleType: Daily, StartTime: "11:00", EndTime: "13:00", MinReplicas: &two, MaxReplicas: &twenty,
+				}),
+			},
+			expected: []string{"contradict each other"},
+		},
+		{
+			name: "overlap",
+			schedule: newTestSchedule("new", ScheduleSpec{
+				ScheduleType: Daily, StartTime: "10:00", EndTime: "12:00", MinReplicas: &five,
+			}),
+			siblings: []Schedule{
+				newTestSchedule("old", ScheduleSpec{ScheduleType: Daily, StartTime: "11:00", EndTime: "13:00", MinReplicas: &two}),
+			},
+			expected: []string{"Schedule new overlaps with Schedule old 28 time(s) from 2018-09-02T11:00Z to 2018-09-02T12:00Z"},
+		},
+		{
+			name: "suspended and self are ignored",
+			schedule: newTestSchedule("new", ScheduleSpec{
+				ScheduleType: Daily, StartTime: "10:00", EndTime: "12:00", MinReplicas: &five,
+			}),
+			siblings: []Schedule{
+				newTestSchedule("new", ScheduleSpec{ScheduleType: Daily, StartTime: "10:00", EndTime: "12:00"}),
+				newTestSchedule("old", ScheduleSpec{
+					ScheduleType: Daily, StartTime: "10:00", EndTime: "12:00", Suspend: true,
+				}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := tt.schedule.OverlapWarnings(tt.siblings, from, to)
+			if len(warnings) != len(tt.expected) {
+				t.Fatalf("warnings mismatch: want: %v, got: %v", tt.expected, warnings)
+			}
+
+			for i, warning := range warnings {
+				if !strings.Contains(warning, tt.expected[i]) {
+					t.Errorf("warning mismatch: want: %s, got: %s", tt.expected[i], warning)
+				}
+			}
+		})
+	}
+}
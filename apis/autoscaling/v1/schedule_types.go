@@ -50,6 +50,15 @@ type ScheduleSpec struct {
 	// +optional
 	TimeZone string `json:"timeZone,omitempty"`
 
+	// Mode is a mode of scheduled scaling represented by "Scale", "Freeze".
+	// Scale sets MinReplicas and MaxReplicas to the HPA during the schedule.
+	// Freeze takes a snapshot of the current replicas of the scale target at the start of the schedule
+	// and pins both min and max replicas of the HPA to it during the schedule.
+	// MinReplicas and MaxReplicas are ignored in Freeze mode. (default is Scale)
+	// +kubebuilder:validation:Enum=Scale;Freeze;""
+	// +optional
+	Mode ScheduleMode `json:"mode,omitempty"`
+
 	// MinReplicas is the lower limit for the number of replicas to which the autoscaler can scale down.
 	// It defaults to 1 pod.
 	// +kubebuilder:validation:Minimum=1
@@ -99,6 +108,13 @@ const (
 	OneShot ScheduleType = "OneShot"
 )
 
+type ScheduleMode string
+
+const (
+	Scale  ScheduleMode = "Scale"
+	Freeze ScheduleMode = "Freeze"
+)
+
 type ScheduleConditionType string
 
 const (
@@ -134,8 +150,43 @@ type ScheduleStatus struct {
 	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
 
 	// Condition is schedule status type.
+	// It summarizes Conditions and is kept for compatibility.
 	// +optional
 	Condition ScheduleConditionType `json:"condition,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the schedule's state.
+	// Supported condition types are "Ready", "Active" and "Degraded".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// SnapshotReplicas is the replicas of the scale target taken at the start of the schedule in Freeze mode.
+	// It is cleared when the schedule ends.
+	// +optional
+	SnapshotReplicas *int32 `json:"snapshotReplicas,omitempty"`
+
+	// LastStartTime is the last time the schedule started.
+	// +optional
+	LastStartTime *metav1.Time `json:"lastStartTime,omitempty"`
+
+	// LastEndTime is the last time the schedule ended.
+	// +optional
+	LastEndTime *metav1.Time `json:"lastEndTime,omitempty"`
+
+	// NextStartTime is the next time the schedule starts.
+	// It is not set if the schedule is suspended or never starts again.
+	// +optional
+	NextStartTime *metav1.Time `json:"nextStartTime,omitempty"`
+
+	// NextEndTime is the next time the schedule ends.
+	// It is not set if the schedule is suspended or never ends again.
+	// +optional
+	NextEndTime *metav1.Time `json:"nextEndTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -148,7 +199,14 @@ type ScheduleStatus struct {
 // +kubebuilder:printcolumn:name="ENDDAYOFWEEK",type=string,JSONPath=`.spec.endDayOfWeek`,priority=0
 // +kubebuilder:printcolumn:name="MINPODS",type=integer,JSONPath=`.spec.minReplicas`,priority=1
 // +kubebuilder:printcolumn:name="MAXPODS",type=integer,JSONPath=`.spec.maxReplicas`,priority=1
+// +kubebuilder:printcolumn:name="MODE",type=string,JSONPath=`.spec.mode`,priority=1
+// +kubebuilder:printcolumn:name="SNAPSHOT",type=integer,JSONPath=`.status.snapshotReplicas`,priority=1
+// +kubebuilder:printcolumn:name="NEXTSTART",type=string,JSONPath=`.status.nextStartTime`,priority=0
+// +kubebuilder:printcolumn:name="NEXTEND",type=string,JSONPath=`.status.nextEndTime`,priority=0
+// +kubebuilder:printcolumn:name="LASTSTART",type=string,JSONPath=`.status.lastStartTime`,priority=1
+// +kubebuilder:printcolumn:name="LASTEND",type=string,JSONPath=`.status.lastEndTime`,priority=1
 // +kubebuilder:printcolumn:name="STATUS",type=string,JSONPath=`.status.condition`,priority=0
+// +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=`.metadata.creationTimestamp`,priority=0
 
 // Schedule is the Schema for the schedules API.
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	clockLayout    = "15:04"
+	dateTimeLayout = "2006-01-02T15:04"
+)
+
+// Validate validates the ScheduleSpec and returns the field errors.
+// It checks the same rules that Contains evaluates at reconcile time,
+// so that an invalid schedule is rejected before it blocks the ScheduledPodAutoscaler.
+func (s *ScheduleSpec) Validate(fldPath *field.Path) field.ErrorList {
+	return s.validate(fldPath, false)
+}
+
+// ValidateUpdate validates the ScheduleSpec updated from the old one and returns the field errors.
+// The same startTime and endTime of a Daily or Weekly schedule are allowed if they are unchanged,
+// so that the schedules created before the rule was introduced can still be updated.
+func (s *ScheduleSpec) ValidateUpdate(old *ScheduleSpec, fldPath *field.Path) field.ErrorList {
+	return s.validate(fldPath, s.StartTime == old.StartTime && s.EndTime == old.EndTime)
+}
+
+func (s *ScheduleSpec) validate(fldPath *field.Path, allowSameClock bool) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if s.ScaleTargetRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("scaleTargetRef", "name"), ""))
+	}
+
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeZone"), s.TimeZone, err.Error()))
+		location = time.UTC
+	}
+
+	if s.MinReplicas != nil && s.MaxReplicas != nil && *s.MinReplicas > *s.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *s.MinReplicas,
+			"must be less than or equal to maxReplicas"))
+	}
+
+	switch s.ScheduleType {
+	case Daily:
+		allErrs = append(allErrs, s.validateClock(fldPath, location, allowSameClock)...)
+	case Weekly:
+		allErrs = append(allErrs, s.validateClock(fldPath, location, allowSameClock)...)
+		allErrs = append(allErrs, s.validateDayOfWeek(fldPath)...)
+	case OneShot:
+		allErrs = append(allErrs, s.validateDateTime(fldPath, location)...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), s.ScheduleType,
+			[]string{string(Weekly), string(Daily), string(OneShot)}))
+	}
+
+	return allErrs
+}
+
+func (s *ScheduleSpec) validateClock(fldPath *field.Path, location *time.Location, allowSame bool) field.ErrorList {
+	var allErrs field.ErrorList
+
+	startTime, startErr := time.ParseInLocation(clockLayout, s.StartTime, location)
+	if startErr != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("startTime"), s.StartTime,
+			"must be in HH:mm format"))
+	}
+
+	endTime, endErr := time.ParseInLocation(clockLayout, s.EndTime, location)
+	if endErr != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("endTime"), s.EndTime,
+			"must be in HH:mm format"))
+	}
+
+	if startErr == nil && endErr == nil && !allowSame && startTime.Equal(endTime) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("endTime"), s.EndTime,
+			"must be different from startTime"))
+	}
+
+	return allErrs
+}
+
+func (s *ScheduleSpec) validateDateTime(fldPath *field.Path, location *time.Location) field.ErrorList {
+	var allErrs field.ErrorList
+
+	startTime, startErr := time.ParseInLocation(dateTimeLayout, s.StartTime, location)
+	if startErr != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("startTime"), s.StartTime,
+			"must be in yyyy-MM-ddTHH:mm format"))
+	}
+
+	endTime, endErr := time.ParseInLocation(dateTimeLayout, s.EndTime, location)
+	if endErr != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("endTime"), s.EndTime,
+			"must be in yyyy-MM-ddTHH:mm format"))
+	}
+
+	if startErr == nil && endErr == nil && !endTime.After(startTime) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("endTime"), s.EndTime,
+			"must be after startTime"))
+	}
+
+	return allErrs
+}
+
+func (s *ScheduleSpec) validateDayOfWeek(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for _, dayOfWeek := range []struct {
+		path  *field.Path
+		value string
+	}{
+		{path: fldPath.Child("startDayOfWeek"), value: s.StartDayOfWeek},
+		{path: fldPath.Child("endDayOfWeek"), value: s.EndDayOfWeek},
+	} {
+		if dayOfWeek.value == "" {
+			allErrs = append(allErrs, field.Required(dayOfWeek.path, "must be specified for Weekly schedule"))
+
+			continue
+		}
+
+		if _, found := weekdays[dayOfWeek.value]; !found {
+			allErrs = append(allErrs, field.NotSupported(dayOfWeek.path, dayOfWeek.value,
+				[]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestScheduleSpecValidate(t *testing.T) {
+	targetRef := autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"}
+	two := int32(2)
+	ten := int32(10)
+
+	tests := []struct {
+		name     string
+		spec     ScheduleSpec
+		expected []string
+	}{
+		{
+			name: "valid daily",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "19:00",
+				TimeZone: "Asia/Tokyo", MinReplicas: &two, MaxReplicas: &ten,
+			},
+		},
+		{
+			name: "valid weekly",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Weekly, StartDayOfWeek: "Monday", EndDayOfWeek: "Friday",
+				StartTime: "10:00", EndTime: "19:00",
+			},
+		},
+		{
+			name: "valid one shot",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: OneShot, StartTime: "2020-01-01T10:00", EndTime: "2020-01-01T19:00",
+			},
+		},
+		{
+			name:     "invalid daily time",
+			spec:     ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "25:00", EndTime: "2020-01-01T19:00"},
+			expected: []string{"spec.startTime", "spec.endTime"},
+		},
+		{
+			name:     "daily start time equals end time",
+			spec:     ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "10:00"},
+			expected: []string{"spec.endTime"},
+		},
+		{
+			name:     "weekly without day of week",
+			spec:     ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: Weekly, StartTime: "10:00", EndTime: "19:00"},
+			expected: []string{"spec.startDayOfWeek", "spec.endDayOfWeek"},
+		},
+		{
+			name: "weekly invalid day of week",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Weekly, StartDayOfWeek: "Mon", EndDayOfWeek: "Friday",
+				StartTime: "10:00", EndTime: "19:00",
+			},
+			expected: []string{"spec.startDayOfWeek"},
+		},
+		{
+			name: "one shot end time before start time",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: OneShot, StartTime: "2020-01-02T10:00", EndTime: "2020-01-01T19:00",
+			},
+			expected: []string{"spec.endTime"},
+		},
+		{
+			name:     "one shot invalid time",
+			spec:     ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: OneShot, StartTime: "10:00", EndTime: "19:00"},
+			expected: []string{"spec.startTime", "spec.endTime"},
+		},
+		{
+			name: "invalid time zone",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "19:00",
+				TimeZone: "Invalid/TimeZone",
+			},
+			expected: []string{"spec.timeZone"},
+		},
+		{
+			name: "min replicas greater than max replicas",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "19:00",
+				MinReplicas: &ten, MaxReplicas: &two,
+			},
+			expected: []string{"spec.minReplicas"},
+		},
+		{
+			name:     "unsupported type and missing target",
+			spec:     ScheduleSpec{ScheduleType: "Monthly", StartTime: "10:00", EndTime: "19:00"},
+			expected: []string{"spec.scaleTargetRef.name", "spec.type"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.spec.Validate(field.NewPath("spec"))
+
+			var actual []string
+			for _, err := range errs {
+				actual = append(actual, err.Field)
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("field errors mismatch (-want +got):\n%s\nerrors: %v", diff, errs)
+			}
+		})
+	}
+}
+
+func TestScheduleSpecValidateUpdate(t *testing.T) {
+	targetRef := autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"}
+	old := ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "10:00"}
+
+	tests := []struct {
+		name     string
+		spec     ScheduleSpec
+		expected []string
+	}{
+		{
+			name: "unchanged start time equals end time",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "10:00", Suspend: true,
+			},
+		},
+		{
+			name: "unchanged start time equals end time with weekly type",
+			spec: ScheduleSpec{
+				ScaleTargetRef: targetRef, ScheduleType: Weekly, StartDayOfWeek: "Monday", EndDayOfWeek: "Friday",
+				StartTime: "10:00", EndTime: "10:00",
+			},
+		},
+		{
+			name:     "changed start time equals end time",
+			spec:     ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "11:00", EndTime: "11:00"},
+			expected: []string{"spec.endTime"},
+		},
+		{
+			name:     "other errors",
+			spec:     ScheduleSpec{ScaleTargetRef: targetRef, ScheduleType: Daily, StartTime: "10:00", EndTime: "10:00", TimeZone: "Invalid/TimeZone"},
+			expected: []string{"spec.timeZone"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.spec.ValidateUpdate(&old, field.NewPath("spec"))
+
+			var actual []string
+			for _, err := range errs {
+				actual = append(actual, err.Field)
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("field errors mismatch (-want +got):\n%s\nerrors: %v", diff, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScheduledPodAutoscalerIsHeld(t *testing.T) {
+	lastScaleTime := metav1.NewTime(time.Date(2018, 9, 10, 10, 00, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		spa      ScheduledPodAutoscaler
+		now      time.Time
+		expected bool
+	}{
+		{
+			name: "case[1]",
+			spa: ScheduledPodAutoscaler{
+				Spec:   ScheduledPodAutoscalerSpec{MinimumHoldDuration: &metav1.Duration{Duration: 10 * time.Minute}},
+				Status: ScheduledPodAutoscalerStatus{LastScaleTime: &lastScaleTime},
+			},
+			now:      time.Date(2018, 9, 10, 10, 05, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			name: "case[2]",
+			spa: ScheduledPodAutoscaler{
+				Spec:   ScheduledPodAutoscalerSpec{MinimumHoldDuration: &metav1.Duration{Duration: 10 * time.Minute}},
+				Status: ScheduledPodAutoscalerStatus{LastScaleTime: &lastScaleTime},
+			},
+			now:      time.Date(2018, 9, 10, 10, 10, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			name: "not scaled yet",
+			spa: ScheduledPodAutoscaler{
+				Spec: ScheduledPodAutoscalerSpec{MinimumHoldDuration: &metav1.Duration{Duration: 10 * time.Minute}},
+			},
+			now:      time.Date(2018, 9, 10, 10, 05, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			name: "minimumHoldDuration is not specified",
+			spa: ScheduledPodAutoscaler{
+				Status: ScheduledPodAutoscalerStatus{LastScaleTime: &lastScaleTime},
+			},
+			now:      time.Date(2018, 9, 10, 10, 05, 0, 0, time.UTC),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if held := tt.spa.IsHeld(tt.now); held != tt.expected {
+				t.Errorf("%s is not expected condition. actual:%t, expected:%t", tt.now, held, tt.expected)
+			}
+		})
+	}
+}
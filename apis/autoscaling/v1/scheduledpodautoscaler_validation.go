@@ -0,0 +1,335 @@
+package v1
+
+import (
+	"fmt"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/validation"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation/path"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// maxStabilizationWindowSeconds and maxPeriodSeconds are the same limits as the validation of the HPA.
+	maxStabilizationWindowSeconds = 3600
+	maxPeriodSeconds              = 1800
+)
+
+// Validate validates the ScheduledPodAutoscalerSpec and returns the field errors.
+// It applies the validation rules of the HPA up front,
+// so that mistakes do not surface only as HPA create/update failures.
+func (s *ScheduledPodAutoscalerSpec) Validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs,
+		validateHorizontalPodAutoscalerSpec(s.HorizontalPodAutoscalerSpec, fldPath.Child("horizontalPodAutoscalerSpec"))...)
+
+	if s.MinimumHoldDuration != nil && s.MinimumHoldDuration.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minimumHoldDuration"), s.MinimumHoldDuration.Duration.String(),
+			"must be greater than or equal to 0"))
+	}
+
+	if s.HPAName != "" {
+		for _, msg := range utilvalidation.IsDNS1123Subdomain(s.HPAName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hpaName"), s.HPAName, msg))
+		}
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(s.HPALabels, fldPath.Child("hpaLabels"))...)
+	allErrs = append(allErrs, validation.ValidateAnnotations(s.HPAAnnotations, fldPath.Child("hpaAnnotations"))...)
+
+	return allErrs
+}
+
+// ValidateSchedules checks that the min/max replicas computed from the ScheduledPodAutoscalerSpec
+// and each Schedule are compatible, e.g. the scheduled max replicas is not below the baseline min replicas.
+func (s *ScheduledPodAutoscalerSpec) ValidateSchedules(schedules []Schedule, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	hpaPath := fldPath.Child("horizontalPodAutoscalerSpec")
+	baselineMinReplicas := int32(1)
+
+	if s.HorizontalPodAutoscalerSpec.MinReplicas != nil {
+		baselineMinReplicas = *s.HorizontalPodAutoscalerSpec.MinReplicas
+	}
+
+	baselineMaxReplicas := s.HorizontalPodAutoscalerSpec.MaxReplicas
+
+	for _, schedule := range schedules {
+		if schedule.Spec.Mode == Freeze {
+			continue
+		}
+
+		if schedule.Spec.MaxReplicas != nil && schedule.Spec.MinReplicas == nil &&
+			*schedule.Spec.MaxReplicas < baselineMinReplicas {
+			allErrs = append(allErrs, field.Invalid(hpaPath.Child("minReplicas"), baselineMinReplicas,
+				fmt.Sprintf("must be less than or equal to maxReplicas %d of Schedule %s",
+					*schedule.Spec.MaxReplicas, schedule.Name)))
+		}
+
+		if schedule.Spec.MinReplicas != nil && schedule.Spec.MaxReplicas == nil &&
+			*schedule.Spec.MinReplicas > baselineMaxReplicas {
+			allErrs = append(allErrs, field.Invalid(hpaPath.Child("maxReplicas"), baselineMaxReplicas,
+				fmt.Sprintf("must be greater than or equal to minReplicas %d of Schedule %s",
+					*schedule.Spec.MinReplicas, schedule.Name)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateHorizontalPodAutoscalerSpec(spec autoscalingv2beta2.HorizontalPodAutoscalerSpec,
+	fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.MinReplicas != nil && *spec.MinReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *spec.MinReplicas,
+			"must be greater than or equal to 1"))
+	}
+
+	if spec.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), spec.MaxReplicas,
+			"must be greater than or equal to 1"))
+	}
+
+	if spec.MinReplicas != nil && spec.MaxReplicas < *spec.MinReplicas {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), spec.MaxReplicas,
+			"must be greater than or equal to minReplicas"))
+	}
+
+	allErrs = append(allErrs, validateCrossVersionObjectReference(spec.ScaleTargetRef, fldPath.Child("scaleTargetRef"))...)
+
+	for i, metric := range spec.Metrics {
+		allErrs = append(allErrs, validateMetricSpec(metric, fldPath.Child("metrics").Index(i))...)
+	}
+
+	if spec.Behavior != nil {
+		allErrs = append(allErrs, validateHPAScalingRules(spec.Behavior.ScaleUp, fldPath.Child("behavior", "scaleUp"))...)
+		allErrs = append(allErrs, validateHPAScalingRules(spec.Behavior.ScaleDown, fldPath.Child("behavior", "scaleDown"))...)
+	}
+
+	return allErrs
+}
+
+func validateCrossVersionObjectReference(ref autoscalingv2beta2.CrossVersionObjectReference,
+	fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if ref.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	} else {
+		for _, msg := range apivalidation.IsValidPathSegmentName(ref.Kind) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("kind"), ref.Kind, msg))
+		}
+	}
+
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	} else {
+		for _, msg := range apivalidation.IsValidPathSegmentName(ref.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), ref.Name, msg))
+		}
+	}
+
+	return allErrs
+}
+
+func validateMetricSpec(spec autoscalingv2beta2.MetricSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	sources := []struct {
+		sourceType autoscalingv2beta2.MetricSourceType
+		path       string
+		set        bool
+	}{
+		{sourceType: autoscalingv2beta2.ObjectMetricSourceType, path: "object", set: spec.Object != nil},
+		{sourceType: autoscalingv2beta2.PodsMetricSourceType, path: "pods", set: spec.Pods != nil},
+		{sourceType: autoscalingv2beta2.ResourceMetricSourceType, path: "resource", set: spec.Resource != nil},
+		{
+			sourceType: autoscalingv2beta2.ContainerResourceMetricSourceType, path: "containerResource",
+			set: spec.ContainerResource != nil,
+		},
+		{sourceType: autoscalingv2beta2.ExternalMetricSourceType, path: "external", set: spec.External != nil},
+	}
+
+	supported := false
+	supportedTypes := make([]string, 0, len(sources))
+
+	for _, source := range sources {
+		supportedTypes = append(supportedTypes, string(source.sourceType))
+
+		if source.sourceType == spec.Type {
+			supported = true
+
+			if !source.set {
+				allErrs = append(allErrs, field.Required(fldPath.Child(source.path),
+					fmt.Sprintf("must populate information for the given metric source %s", spec.Type)))
+			}
+		} else if source.set {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child(source.path),
+				"must populate the given metric source only"))
+		}
+	}
+
+	if spec.Type == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("type"), "must specify a metric source type"))
+	} else if !supported {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), spec.Type, supportedTypes))
+	}
+
+	if spec.Object != nil {
+		path := fldPath.Child("object")
+		allErrs = append(allErrs, validateCrossVersionObjectReference(spec.Object.DescribedObject, path.Child("describedObject"))...)
+		allErrs = append(allErrs, validateMetricIdentifier(spec.Object.Metric, path.Child("metric"))...)
+		allErrs = append(allErrs, validateMetricTarget(spec.Object.Target, path.Child("target"), false)...)
+	}
+
+	if spec.Pods != nil {
+		path := fldPath.Child("pods")
+		allErrs = append(allErrs, validateMetricIdentifier(spec.Pods.Metric, path.Child("metric"))...)
+		allErrs = append(allErrs, validateMetricTarget(spec.Pods.Target, path.Child("target"), false)...)
+	}
+
+	if spec.Resource != nil {
+		path := fldPath.Child("resource")
+		if spec.Resource.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "must specify a resource name"))
+		}
+
+		allErrs = append(allErrs, validateMetricTarget(spec.Resource.Target, path.Child("target"), true)...)
+	}
+
+	if spec.ContainerResource != nil {
+		path := fldPath.Child("containerResource")
+		if spec.ContainerResource.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "must specify a resource name"))
+		}
+
+		if spec.ContainerResource.Container == "" {
+			allErrs = append(allErrs, field.Required(path.Child("container"), "must specify a container"))
+		}
+
+		allErrs = append(allErrs, validateMetricTarget(spec.ContainerResource.Target, path.Child("target"), true)...)
+	}
+
+	if spec.External != nil {
+		path := fldPath.Child("external")
+		allErrs = append(allErrs, validateMetricIdentifier(spec.External.Metric, path.Child("metric"))...)
+		allErrs = append(allErrs, validateMetricTarget(spec.External.Target, path.Child("target"), false)...)
+	}
+
+	return allErrs
+}
+
+func validateMetricIdentifier(id autoscalingv2beta2.MetricIdentifier, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if id.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "must specify a metric name"))
+	}
+
+	return allErrs
+}
+
+func validateMetricTarget(target autoscalingv2beta2.MetricTarget, fldPath *field.Path,
+	allowUtilization bool) field.ErrorList {
+	var allErrs field.ErrorList
+
+	typePath := fldPath.Child("type")
+
+	switch target.Type {
+	case "":
+		allErrs = append(allErrs, field.Required(typePath, "must specify a metric target type"))
+	case autoscalingv2beta2.UtilizationMetricType:
+		if !allowUtilization {
+			allErrs = append(allErrs, field.Invalid(typePath, target.Type,
+				"utilization is only supported for resource metrics"))
+		}
+
+		if target.AverageUtilization == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("averageUtilization"),
+				"must set averageUtilization for Utilization target type"))
+		}
+	case autoscalingv2beta2.ValueMetricType:
+		if target.Value == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("value"), "must set value for Value target type"))
+		}
+	case autoscalingv2beta2.AverageValueMetricType:
+		if target.AverageValue == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("averageValue"),
+				"must set averageValue for AverageValue target type"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(typePath, target.Type, []string{
+			string(autoscalingv2beta2.UtilizationMetricType), string(autoscalingv2beta2.ValueMetricType),
+			string(autoscalingv2beta2.AverageValueMetricType),
+		}))
+	}
+
+	if target.AverageUtilization != nil && *target.AverageUtilization < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("averageUtilization"), *target.AverageUtilization,
+			"must be greater than 0"))
+	}
+
+	if target.Value != nil && target.Value.Sign() != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), target.Value.String(), "must be positive"))
+	}
+
+	if target.AverageValue != nil && target.AverageValue.Sign() != 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("averageValue"), target.AverageValue.String(),
+			"must be positive"))
+	}
+
+	return allErrs
+}
+
+func validateHPAScalingRules(rules *autoscalingv2beta2.HPAScalingRules, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if rules == nil {
+		return allErrs
+	}
+
+	if rules.StabilizationWindowSeconds != nil &&
+		(*rules.StabilizationWindowSeconds < 0 || *rules.StabilizationWindowSeconds > maxStabilizationWindowSeconds) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("stabilizationWindowSeconds"),
+			*rules.StabilizationWindowSeconds,
+			fmt.Sprintf("must be between 0 and %d", maxStabilizationWindowSeconds)))
+	}
+
+	if rules.SelectPolicy != nil {
+		switch *rules.SelectPolicy {
+		case autoscalingv2beta2.MaxPolicySelect, autoscalingv2beta2.MinPolicySelect, autoscalingv2beta2.DisabledPolicySelect:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("selectPolicy"), *rules.SelectPolicy, []string{
+				string(autoscalingv2beta2.MaxPolicySelect), string(autoscalingv2beta2.MinPolicySelect),
+				string(autoscalingv2beta2.DisabledPolicySelect),
+			}))
+		}
+	}
+
+	for i, policy := range rules.Policies {
+		path := fldPath.Child("policies").Index(i)
+
+		switch policy.Type {
+		case autoscalingv2beta2.PodsScalingPolicy, autoscalingv2beta2.PercentScalingPolicy:
+		default:
+			allErrs = append(allErrs, field.NotSupported(path.Child("type"), policy.Type, []string{
+				string(autoscalingv2beta2.PodsScalingPolicy), string(autoscalingv2beta2.PercentScalingPolicy),
+			}))
+		}
+
+		if policy.Value <= 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("value"), policy.Value, "must be greater than 0"))
+		}
+
+		if policy.PeriodSeconds <= 0 || policy.PeriodSeconds > maxPeriodSeconds {
+			allErrs = append(allErrs, field.Invalid(path.Child("periodSeconds"), policy.PeriodSeconds,
+				fmt.Sprintf("must be between 1 and %d", maxPeriodSeconds)))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestScheduledPodAutoscalerSpecValidate(t *testing.T) {
+	targetRef := autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx", APIVersion: "apps/v1"}
+	one := int32(1)
+	three := int32(3)
+	fifty := int32(50)
+	invalidPolicy := autoscalingv2beta2.ScalingPolicySelect("Invalid")
+
+	tests := []struct {
+		name     string
+		spec     ScheduledPodAutoscalerSpec
+		expected []string
+	}{
+		{
+			name: "valid",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: targetRef, MinReplicas: &one, MaxReplicas: 3,
+					Metrics: []autoscalingv2beta2.MetricSpec{
+						{
+							Type: autoscalingv2beta2.ResourceMetricSourceType,
+							Resource: &autoscalingv2beta2.ResourceMetricSource{
+								Name: corev1.ResourceCPU,
+								Target: autoscalingv2beta2.MetricTarget{
+									Type: autoscalingv2beta2.UtilizationMetricType, AverageUtilization: &fifty,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "min replicas greater than max replicas",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: targetRef, MinReplicas: &three, MaxReplicas: 1,
+				},
+			},
+			expected: []string{"spec.horizontalPodAutoscalerSpec.maxReplicas"},
+		},
+		{
+			name: "missing scale target kind",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"}, MaxReplicas: 1,
+				},
+			},
+			expected: []string{"spec.horizontalPodAutoscalerSpec.scaleTargetRef.kind"},
+		},
+		{
+			name: "malformed metrics",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: targetRef, MaxReplicas: 1,
+					Metrics: []autoscalingv2beta2.MetricSpec{
+						{
+							Type: autoscalingv2beta2.PodsMetricSourceType,
+							Resource: &autoscalingv2beta2.ResourceMetricSource{
+								Name:   corev1.ResourceCPU,
+								Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.ValueMetricType},
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"spec.horizontalPodAutoscalerSpec.metrics[0].pods",
+				"spec.horizontalPodAutoscalerSpec.metrics[0].resource",
+				"spec.horizontalPodAutoscalerSpec.metrics[0].resource.target.value",
+			},
+		},
+		{
+			name: "invalid behavior",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: targetRef, MaxReplicas: 1,
+					Behavior: &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+						ScaleDown: &autoscalingv2beta2.HPAScalingRules{
+							SelectPolicy: &invalidPolicy,
+							Policies: []autoscalingv2beta2.HPAScalingPolicy{
+								{Type: autoscalingv2beta2.PodsScalingPolicy, Value: 0, PeriodSeconds: 60},
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				"spec.horizontalPodAutoscalerSpec.behavior.scaleDown.selectPolicy",
+				"spec.horizontalPodAutoscalerSpec.behavior.scaleDown.policies[0].value",
+			},
+		},
+		{
+			name: "negative minimum hold duration",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: targetRef, MaxReplicas: 1,
+				},
+				MinimumHoldDuration: &metav1.Duration{Duration: -1},
+			},
+			expected: []string{"spec.minimumHoldDuration"},
+		},
+		{
+			name: "invalid HPA metadata",
+			spec: ScheduledPodAutoscalerSpec{
+				HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: targetRef, MaxReplicas: 1,
+				},
+				HPAName:        "Invalid_Name",
+				HPALabels:      map[string]string{"team": "web/app"},
+				HPAAnnotations: map[string]string{"invalid key": "value"},
+			},
+			expected: []string{"spec.hpaName", "spec.hpaLabels", "spec.hpaAnnotations"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.spec.Validate(field.NewPath("spec"))
+
+			var actual []string
+			for _, err := range errs {
+				actual = append(actual, err.Field)
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("field errors mismatch (-want +got):\n%s\nerrors: %v", diff, errs)
+			}
+		})
+	}
+}
+
+func TestScheduledPodAutoscalerSpecValidateSchedules(t *testing.T) {
+	two := int32(2)
+	five := int32(5)
+	twenty := int32(20)
+
+	spec := ScheduledPodAutoscalerSpec{
+		HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{MinReplicas: &five, MaxReplicas: 10},
+	}
+
+	tests := []struct {
+		name      string
+		schedules []Schedule
+		expected  []string
+	}{
+		{
+			name: "compatible",
+			schedules: []Schedule{
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-1"}, Spec: ScheduleSpec{MinReplicas: &two, MaxReplicas: &five}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-2"}, Spec: ScheduleSpec{MaxReplicas: &twenty}},
+			},
+		},
+		{
+			name: "scheduled max replicas below baseline min replicas",
+			schedules: []Schedule{
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-1"}, Spec: ScheduleSpec{MaxReplicas: &two}},
+			},
+			expected: []string{"spec.horizontalPodAutoscalerSpec.minReplicas"},
+		},
+		{
+			name: "scheduled min replicas above baseline max replicas",
+			schedules: []Schedule{
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-1"}, Spec: ScheduleSpec{MinReplicas: &twenty}},
+			},
+			expected: []string{"spec.horizontalPodAutoscalerSpec.maxReplicas"},
+		},
+		{
+			name: "freeze mode is ignored",
+			schedules: []Schedule{
+				{ObjectMeta: metav1.ObjectMeta{Name: "test-1"}, Spec: ScheduleSpec{Mode: Freeze, MaxReplicas: &two}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			errs := spec.ValidateSchedules(tt.schedules, field.NewPath("spec"))
+
+			var actual []string
+			for _, err := range errs {
+				actual = append(actual, err.Field)
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("field errors mismatch (-want +got):\n%s\nerrors: %v", diff, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduleTransitions is the last and next times at which the schedule starts and ends.
+// The zero time means that there is no such time.
+// +kubebuilder:object:generate=false
+type ScheduleTransitions struct {
+	LastStartTime time.Time
+	LastEndTime   time.Time
+	NextStartTime time.Time
+	NextEndTime   time.Time
+}
+
+// Next returns the earliest time of the next start time and the next end time.
+// The zero time is returned if the schedule never transitions again.
+func (t ScheduleTransitions) Next() time.Time {
+	switch {
+	case t.NextStartTime.IsZero():
+		return t.NextEndTime
+	case t.NextEndTime.IsZero():
+		return t.NextStartTime
+	case t.NextStartTime.Before(t.NextEndTime):
+		return t.NextStartTime
+	default:
+		return t.NextEndTime
+	}
+}
+
+// ScheduleWindow is a period [Start, End) in which the schedule is active.
+// +kubebuilder:object:generate=false
+type ScheduleWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// transitionDays is the number of days before and after now to find the transitions.
+// A week is enough to find the last and next transitions for both Daily and Weekly.
+const transitionDays = 8
+
+// Transitions calculates the last and next start/end times of the schedule relative to now.
+// A start or end time equal to now is treated as the last one.
+func (s *ScheduleSpec) Transitions(now time.Time) (ScheduleTransitions, error) {
+	location, err := s.loadLocation()
+	if err != nil {
+		return ScheduleTransitions{}, err
+	}
+
+	now = now.In(location)
+
+	windows, err := s.windows(now, location, transitionDays)
+	if err != nil {
+		return ScheduleTransitions{}, err
+	}
+
+	var transitions ScheduleTransitions
+
+	for _, window := range windows {
+		transitions.LastStartTime, transitions.NextStartTime =
+			updateTransition(now, window.Start, transitions.LastStartTime, transitions.NextStartTime)
+		transitions.LastEndTime, transitions.NextEndTime =
+			updateTransition(now, window.End, transitions.LastEndTime, transitions.NextEndTime)
+	}
+
+	return transitions, nil
+}
+
+// Windows returns the windows of the schedule that overlap with the period [from, to).
+func (s *ScheduleSpec) Windows(from time.Time, to time.Time) ([]ScheduleWindow, error) {
+	location, err := s.loadLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	from = from.In(location)
+	days := int(to.Sub(from).Hours()/24) + 1
+
+	windows, err := s.windows(from, location, days)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ScheduleWindow, 0, len(windows))
+
+	for _, window := range windows {
+		if window.End.After(from) && window.Start.Before(to) {
+			result = append(result, window)
+		}
+	}
+
+	return result, nil
+}
+
+// windows returns the non-empty windows of the schedule starting from the days before now to the days after now.
+func (s *ScheduleSpec) windows(now time.Time, location *time.Location, days int) ([]ScheduleWindow, error) {
+	var (
+		windows []ScheduleWindow
+		err     error
+	)
+
+	switch s.ScheduleType {
+	case Daily:
+		windows, err = s.dailyWindows(now, location, days)
+	case Weekly:
+		windows, err = s.weeklyWindows(now, location, days)
+	case OneShot:
+		windows, err = s.oneShotWindows(location)
+	default:
+		err = fmt.Errorf("unsupported schedule types: %s", s.ScheduleType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ScheduleWindow, 0, len(windows))
+
+	for _, window := range windows {
+		if window.Start.Before(window.End) {
+			result = append(result, window)
+		}
+	}
+
+	return result, nil
+}
+
+func updateTransition(now time.Time, t time.Time, last time.Time, next time.Time) (time.Time, time.Time) {
+	if t.After(now) {
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	} else if last.IsZero() || t.After(last) {
+		last = t
+	}
+
+	return last, next
+}
+
+// dailyWindows returns the windows starting from the days before now to the days after now.
+func (s *ScheduleSpec) dailyWindows(now time.Time, location *time.Location, days int) ([]ScheduleWindow, error) {
+	startTime, err := time.ParseInLocation("15:04", s.StartTime, location)
+	if err != nil {
+		return nil, fmt.Errorf("startTime cannot be parsed: %w", err)
+	}
+
+	endTime, err := time.ParseInLocation("15:04", s.EndTime, location)
+	if err != nil {
+		return nil, fmt.Errorf("endTime cannot be parsed: %w", err)
+	}
+
+	windows := make([]ScheduleWindow, 0, days*2+1)
+
+	for i := -days; i <= days; i++ {
+		day := now.AddDate(0, 0, i)
+
+		start := time.Date(day.Year(), day.Month(), day.Day(),
+			startTime.Hour(), startTime.Minute(), 0, 0, location)
+
+		end := time.Date(day.Year(), day.Month(), day.Day(),
+			endTime.Hour(), endTime.Minute(), 0, 0, location)
+
+		if end.Before(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+
+		windows = append(windows, ScheduleWindow{Start: start, End: end})
+	}
+
+	return windows, nil
+}
+
+func (s *ScheduleSpec) weeklyWindows(now time.Time, location *time.Location, days int) ([]ScheduleWindow, error) {
+	dailyWindows, err := s.dailyWindows(now, location, days)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]ScheduleWindow, 0, len(dailyWindows))
+
+	for _, window := range dailyWindows {
+		weekdayToday, startWeekDay, endWeekDay, err := s.normalizeWeekday(window.Start)
+		if err != nil {
+			return nil, err
+		}
+
+		if startWeekDay <= weekdayToday && weekdayToday <= endWeekDay {
+			windows = append(windows, window)
+		}
+	}
+
+	return windows, nil
+}
+
+func (s *ScheduleSpec) oneShotWindows(location *time.Location) ([]ScheduleWindow, error) {
+	startTime, err := time.ParseInLocation("2006-01-02T15:04", s.StartTime, location)
+	if err != nil {
+		return nil, fmt.Errorf("startTime cannot be parsed: %w", err)
+	}
+
+	endTime, err := time.ParseInLocation("2006-01-02T15:04", s.EndTime, location)
+	if err != nil {
+		return nil, fmt.Errorf("endTime cannot be parsed: %w", err)
+	}
+
+	return []ScheduleWindow{{Start: startTime, End: endTime}}, nil
+}
@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScheduleSpecTransitions(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		spec     ScheduleSpec
+		now      time.Time
+		expected ScheduleTransitions
+	}{
+		{
+			name: "daily active",
+			spec: ScheduleSpec{ScheduleType: Daily, StartTime: "10:00", EndTime: "19:00"},
+			now:  time.Date(2018, 9, 2, 11, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				LastStartTime: time.Date(2018, 9, 2, 10, 00, 0, 0, time.UTC),
+				LastEndTime:   time.Date(2018, 9, 1, 19, 00, 0, 0, time.UTC),
+				NextStartTime: time.Date(2018, 9, 3, 10, 00, 0, 0, time.UTC),
+				NextEndTime:   time.Date(2018, 9, 2, 19, 00, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "daily inactive",
+			spec: ScheduleSpec{ScheduleType: Daily, StartTime: "10:00", EndTime: "19:00"},
+			now:  time.Date(2018, 9, 2, 20, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				LastStartTime: time.Date(2018, 9, 2, 10, 00, 0, 0, time.UTC),
+				LastEndTime:   time.Date(2018, 9, 2, 19, 00, 0, 0, time.UTC),
+				NextStartTime: time.Date(2018, 9, 3, 10, 00, 0, 0, time.UTC),
+				NextEndTime:   time.Date(2018, 9, 3, 19, 00, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "daily date changes",
+			spec: ScheduleSpec{ScheduleType: Daily, StartTime: "23:00", EndTime: "03:00"},
+			now:  time.Date(2018, 9, 2, 02, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				LastStartTime: time.Date(2018, 9, 1, 23, 00, 0, 0, time.UTC),
+				LastEndTime:   time.Date(2018, 9, 1, 03, 00, 0, 0, time.UTC),
+				NextStartTime: time.Date(2018, 9, 2, 23, 00, 0, 0, time.UTC),
+				NextEndTime:   time.Date(2018, 9, 2, 03, 00, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "weekly",
+			spec: ScheduleSpec{
+				ScheduleType: Weekly, StartDayOfWeek: "Monday", EndDayOfWeek: "Tuesday",
+				StartTime: "15:00", EndTime: "01:30",
+			},
+			// Thursday
+			now: time.Date(2018, 9, 6, 16, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				LastStartTime: time.Date(2018, 9, 4, 15, 00, 0, 0, time.UTC),
+				LastEndTime:   time.Date(2018, 9, 5, 01, 30, 0, 0, time.UTC),
+				NextStartTime: time.Date(2018, 9, 10, 15, 00, 0, 0, time.UTC),
+				NextEndTime:   time.Date(2018, 9, 11, 01, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "weekly with time zone",
+			spec: ScheduleSpec{
+				ScheduleType: Weekly, StartDayOfWeek: "Monday", EndDayOfWeek: "Monday",
+				StartTime: "10:00", EndTime: "19:00", TimeZone: "Asia/Tokyo",
+			},
+			// Monday 09:00 in Asia/Tokyo
+			now: time.Date(2018, 9, 3, 0, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				LastStartTime: time.Date(2018, 8, 27, 10, 00, 0, 0, tokyo),
+				LastEndTime:   time.Date(2018, 8, 27, 19, 00, 0, 0, tokyo),
+				NextStartTime: time.Date(2018, 9, 3, 10, 00, 0, 0, tokyo),
+				NextEndTime:   time.Date(2018, 9, 3, 19, 00, 0, 0, tokyo),
+			},
+		},
+		{
+			name: "one shot before start",
+			spec: ScheduleSpec{ScheduleType: OneShot, StartTime: "2018-09-01T10:00", EndTime: "2018-09-10T19:00"},
+			now:  time.Date(2018, 8, 10, 11, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				NextStartTime: time.Date(2018, 9, 1, 10, 00, 0, 0, time.UTC),
+				NextEndTime:   time.Date(2018, 9, 10, 19, 00, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "one shot completed",
+			spec: ScheduleSpec{ScheduleType: OneShot, StartTime: "2018-09-01T10:00", EndTime: "2018-09-10T19:00"},
+			now:  time.Date(2018, 9, 10, 20, 00, 0, 0, time.UTC),
+			expected: ScheduleTransitions{
+				LastStartTime: time.Date(2018, 9, 1, 10, 00, 0, 0, time.UTC),
+				LastEndTime:   time.Date(2018, 9, 10, 19, 00, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			transitions, err := tt.spec.Transitions(tt.now)
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			equal := cmp.Comparer(func(x, y time.Time) bool { return x.Equal(y) })
+			if diff := cmp.Diff(tt.expected, transitions, equal); diff != "" {
+				t.Errorf("transitions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScheduleTransitionsNext(t *testing.T) {
+	start := time.Date(2018, 9, 1, 10, 00, 0, 0, time.UTC)
+	end := time.Date(2018, 9, 1, 19, 00, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		transitions ScheduleTransitions
+		expected    time.Time
+	}{
+		{
+			name:        "next start is earlier",
+			transitions: ScheduleTransitions{NextStartTime: start, NextEndTime: end},
+			expected:    start,
+		},
+		{
+			name:        "next end is earlier",
+			transitions: ScheduleTransitions{NextStartTime: end.AddDate(0, 0, 1), NextEndTime: end},
+			expected:    end,
+		},
+		{
+			name:        "no next start",
+			transitions: ScheduleTransitions{NextEndTime: end},
+			expected:    end,
+		},
+		{
+			name:        "no transitions",
+			transitions: ScheduleTransitions{},
+			expected:    time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if next := tt.transitions.Next(); !next.Equal(tt.expected) {
+				t.Errorf("next transition mismatch. actual:%s, expected:%s", next, tt.expected)
+			}
+		})
+	}
+}
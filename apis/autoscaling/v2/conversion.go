@@ -0,0 +1,7 @@
+package v2
+
+// Hub marks this type as a conversion hub.
+func (*Schedule) Hub() {}
+
+// Hub marks this type as a conversion hub.
+func (*ScheduledPodAutoscaler) Hub() {}
@@ -0,0 +1,220 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScheduleSpec defines the desired state of Schedule.
+type ScheduleSpec struct {
+	// ScaleTargetRef points to the ScheduledPodAutoscaler to which the schedule applies.
+	// +kubebuilder:validation:Required
+	ScaleTargetRef autoscalingv2beta2.CrossVersionObjectReference `json:"scaleTargetRef"`
+
+	// Suspend indicates whether to suspend this schedule. (default is false)
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Description is schedule description.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// TimeZone is the name of the timezone used in the argument of the time.LoadLocation(name string) function.
+	// The times of the Recurrence are interpreted as the time in the time zone specified by TimeZone.
+	// If not specified, the time will be interpreted as UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Mode is a mode of scheduled scaling represented by "Scale", "Freeze".
+	// Scale sets MinReplicas and MaxReplicas to the HPA during the schedule.
+	// Freeze takes a snapshot of the current replicas of the scale target at the start of the schedule
+	// and pins both min and max replicas of the HPA to it during the schedule.
+	// MinReplicas and MaxReplicas are ignored in Freeze mode. (default is Scale)
+	// +kubebuilder:validation:Enum=Scale;Freeze;""
+	// +optional
+	Mode ScheduleMode `json:"mode,omitempty"`
+
+	// MinReplicas is the lower limit for the number of replicas to which the autoscaler can scale down.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas to which the autoscaler can scale up.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// Recurrence defines when the schedule is active.
+	// +kubebuilder:validation:Required
+	Recurrence Recurrence `json:"recurrence"`
+}
+
+// Recurrence defines when the schedule is active.
+type Recurrence struct {
+	// Type is a type of recurrence represented by "Weekly", "Daily", "OneShot".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Weekly;Daily;OneShot
+	Type RecurrenceType `json:"type"`
+
+	// DaysOfWeek is the list of days of week on which a Weekly schedule starts.
+	// The days must be consecutive, e.g. Friday, Saturday and Sunday.
+	// +optional
+	DaysOfWeek []DayOfWeek `json:"daysOfWeek,omitempty"`
+
+	// StartTime is the time of day at which a Daily or Weekly schedule starts.
+	// +optional
+	StartTime *TimeOfDay `json:"startTime,omitempty"`
+
+	// EndTime is the time of day at which a Daily or Weekly schedule ends.
+	// If EndTime is before StartTime, the schedule ends on the next day.
+	// +optional
+	EndTime *TimeOfDay `json:"endTime,omitempty"`
+
+	// StartDateTime is the date and time at which a OneShot schedule starts. e.g. 2020-09-01T10:00
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}$`
+	// +optional
+	StartDateTime string `json:"startDateTime,omitempty"`
+
+	// EndDateTime is the date and time at which a OneShot schedule ends. e.g. 2020-09-01T12:00
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}$`
+	// +optional
+	EndDateTime string `json:"endDateTime,omitempty"`
+}
+
+// TimeOfDay is a time of day.
+type TimeOfDay struct {
+	// Hour is the hour of day.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	Hour int32 `json:"hour"`
+
+	// Minute is the minute of hour.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=59
+	Minute int32 `json:"minute"`
+}
+
+type RecurrenceType string
+
+const (
+	Weekly  RecurrenceType = "Weekly"
+	Daily   RecurrenceType = "Daily"
+	OneShot RecurrenceType = "OneShot"
+)
+
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type DayOfWeek string
+
+const (
+	Sunday    DayOfWeek = "Sunday"
+	Monday    DayOfWeek = "Monday"
+	Tuesday   DayOfWeek = "Tuesday"
+	Wednesday DayOfWeek = "Wednesday"
+	Thursday  DayOfWeek = "Thursday"
+	Friday    DayOfWeek = "Friday"
+	Saturday  DayOfWeek = "Saturday"
+)
+
+// DaysOfWeek is the days of week in order from Sunday.
+var DaysOfWeek = []DayOfWeek{Sunday, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday}
+
+type ScheduleMode string
+
+const (
+	Scale  ScheduleMode = "Scale"
+	Freeze ScheduleMode = "Freeze"
+)
+
+// ScheduleStatus defines the observed state of Schedule.
+type ScheduleStatus struct {
+	// Conditions represent the latest available observations of the schedule's state.
+	// Supported condition types are "Ready", "Active" and "Degraded".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SnapshotReplicas is the replicas of the scale target taken at the start of the schedule in Freeze mode.
+	// It is cleared when the schedule ends.
+	// +optional
+	SnapshotReplicas *int32 `json:"snapshotReplicas,omitempty"`
+
+	// LastStartTime is the last time the schedule started.
+	// +optional
+	LastStartTime *metav1.Time `json:"lastStartTime,omitempty"`
+
+	// LastEndTime is the last time the schedule ended.
+	// +optional
+	LastEndTime *metav1.Time `json:"lastEndTime,omitempty"`
+
+	// NextStartTime is the next time the schedule starts.
+	// It is not set if the schedule is suspended or never starts again.
+	// +optional
+	NextStartTime *metav1.Time `json:"nextStartTime,omitempty"`
+
+	// NextEndTime is the next time the schedule ends.
+	// It is not set if the schedule is suspended or never ends again.
+	// +optional
+	NextEndTime *metav1.Time `json:"nextEndTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="REFERENCE",type=string,JSONPath=`.spec.scaleTargetRef.name`,priority=0
+// +kubebuilder:printcolumn:name="TYPE",type=string,JSONPath=`.spec.recurrence.type`,priority=0
+// +kubebuilder:printcolumn:name="DAYSOFWEEK",type=string,JSONPath=`.spec.recurrence.daysOfWeek`,priority=1
+// +kubebuilder:printcolumn:name="MINPODS",type=integer,JSONPath=`.spec.minReplicas`,priority=1
+// +kubebuilder:printcolumn:name="MAXPODS",type=integer,JSONPath=`.spec.maxReplicas`,priority=1
+// +kubebuilder:printcolumn:name="MODE",type=string,JSONPath=`.spec.mode`,priority=1
+// +kubebuilder:printcolumn:name="SNAPSHOT",type=integer,JSONPath=`.status.snapshotReplicas`,priority=1
+// +kubebuilder:printcolumn:name="NEXTSTART",type=string,JSONPath=`.status.nextStartTime`,priority=0
+// +kubebuilder:printcolumn:name="NEXTEND",type=string,JSONPath=`.status.nextEndTime`,priority=0
+// +kubebuilder:printcolumn:name="LASTSTART",type=string,JSONPath=`.status.lastStartTime`,priority=1
+// +kubebuilder:printcolumn:name="LASTEND",type=string,JSONPath=`.status.lastEndTime`,priority=1
+// +kubebuilder:printcolumn:name="ACTIVE",type=string,JSONPath=`.status.conditions[?(@.type=="Active")].status`,priority=0
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=0
+// +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=`.metadata.creationTimestamp`,priority=0
+
+// Schedule is the Schema for the schedules API.
+type Schedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduleSpec   `json:"spec,omitempty"`
+	Status ScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScheduleList contains a list of Schedule.
+type ScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Schedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Schedule{}, &ScheduleList{})
+}
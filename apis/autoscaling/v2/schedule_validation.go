@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate validates the Recurrence and returns the field errors.
+// The days of week of a Weekly recurrence must be consecutive, e.g. Friday, Saturday and Sunday,
+// since the controller evaluates a Weekly schedule as the range from the first day to the last day.
+func (r *Recurrence) Validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	switch r.Type {
+	case Daily:
+		allErrs = append(allErrs, r.validateTimeOfDay(fldPath)...)
+	case Weekly:
+		allErrs = append(allErrs, r.validateTimeOfDay(fldPath)...)
+		allErrs = append(allErrs, r.validateDaysOfWeek(fldPath)...)
+	case OneShot:
+		if r.StartDateTime == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("startDateTime"), "must be specified for OneShot recurrence"))
+		}
+
+		if r.EndDateTime == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("endDateTime"), "must be specified for OneShot recurrence"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), r.Type,
+			[]string{string(Weekly), string(Daily), string(OneShot)}))
+	}
+
+	return allErrs
+}
+
+func (r *Recurrence) validateTimeOfDay(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.StartTime == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("startTime"), "must be specified for Daily or Weekly recurrence"))
+	}
+
+	if r.EndTime == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("endTime"), "must be specified for Daily or Weekly recurrence"))
+	}
+
+	return allErrs
+}
+
+func (r *Recurrence) validateDaysOfWeek(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	path := fldPath.Child("daysOfWeek")
+
+	if len(r.DaysOfWeek) == 0 {
+		return append(allErrs, field.Required(path, "must be specified for Weekly recurrence"))
+	}
+
+	var set [7]bool
+
+	for i, day := range r.DaysOfWeek {
+		weekday := dayOfWeekIndex(day)
+		if weekday < 0 {
+			allErrs = append(allErrs, field.NotSupported(path.Index(i), day,
+				[]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}))
+
+			continue
+		}
+
+		if set[weekday] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), day))
+
+			continue
+		}
+
+		set[weekday] = true
+	}
+
+	if len(allErrs) == 0 && !isConsecutive(set, len(r.DaysOfWeek)) {
+		allErrs = append(allErrs, field.Invalid(path, r.DaysOfWeek, "must be consecutive days of week"))
+	}
+
+	return allErrs
+}
+
+// isConsecutive returns true if the days in the set are consecutive, wrapping around from Saturday to Sunday.
+func isConsecutive(set [7]bool, days int) bool {
+	if days == len(set) {
+		return true
+	}
+
+	for start := range set {
+		if !set[start] || set[(start+6)%7] {
+			continue
+		}
+
+		for i := 0; i < days; i++ {
+			if !set[(start+i)%7] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func dayOfWeekIndex(day DayOfWeek) int {
+	for i, d := range DaysOfWeek {
+		if d == day {
+			return i
+		}
+	}
+
+	return -1
+}
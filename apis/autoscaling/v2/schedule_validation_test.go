@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestRecurrenceValidate(t *testing.T) {
+	start := &TimeOfDay{Hour: 10}
+	end := &TimeOfDay{Hour: 19, Minute: 30}
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		expected   []string
+	}{
+		{
+			name:       "valid daily",
+			recurrence: Recurrence{Type: Daily, StartTime: start, EndTime: end},
+		},
+		{
+			name:       "valid weekly",
+			recurrence: Recurrence{Type: Weekly, DaysOfWeek: []DayOfWeek{Monday, Tuesday, Wednesday}, StartTime: start, EndTime: end},
+		},
+		{
+			name:       "valid weekly across the weekend",
+			recurrence: Recurrence{Type: Weekly, DaysOfWeek: []DayOfWeek{Saturday, Sunday, Friday}, StartTime: start, EndTime: end},
+		},
+		{
+			name: "valid weekly with all days",
+			recurrence: Recurrence{
+				Type: Weekly, DaysOfWeek: []DayOfWeek{Sunday, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday},
+				StartTime: start, EndTime: end,
+			},
+		},
+		{
+			name:       "valid one shot",
+			recurrence: Recurrence{Type: OneShot, StartDateTime: "2020-09-01T10:00", EndDateTime: "2020-09-01T12:00"},
+		},
+		{
+			name:       "weekly with non-consecutive days",
+			recurrence: Recurrence{Type: Weekly, DaysOfWeek: []DayOfWeek{Monday, Wednesday, Friday}, StartTime: start, EndTime: end},
+			expected:   []string{"spec.recurrence.daysOfWeek"},
+		},
+		{
+			name:       "weekly with duplicated days",
+			recurrence: Recurrence{Type: Weekly, DaysOfWeek: []DayOfWeek{Monday, Monday}, StartTime: start, EndTime: end},
+			expected:   []string{"spec.recurrence.daysOfWeek[1]"},
+		},
+		{
+			name:       "weekly with unsupported day",
+			recurrence: Recurrence{Type: Weekly, DaysOfWeek: []DayOfWeek{"Mon"}, StartTime: start, EndTime: end},
+			expected:   []string{"spec.recurrence.daysOfWeek[0]"},
+		},
+		{
+			name:       "weekly without days",
+			recurrence: Recurrence{Type: Weekly, StartTime: start, EndTime: end},
+			expected:   []string{"spec.recurrence.daysOfWeek"},
+		},
+		{
+			name:       "daily without times",
+			recurrence: Recurrence{Type: Daily},
+			expected:   []string{"spec.recurrence.startTime", "spec.recurrence.endTime"},
+		},
+		{
+			name:       "one shot without date times",
+			recurrence: Recurrence{Type: OneShot},
+			expected:   []string{"spec.recurrence.startDateTime", "spec.recurrence.endDateTime"},
+		},
+		{
+			name:       "unsupported type",
+			recurrence: Recurrence{Type: "Monthly"},
+			expected:   []string{"spec.recurrence.type"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.recurrence.Validate(field.NewPath("spec", "recurrence"))
+
+			var actual []string
+			for _, err := range errs {
+				actual = append(actual, err.Field)
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("field errors mismatch (-want +got):\n%s\nerrors: %v", diff, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScheduledPodAutoscalerSpec defines the desired state of ScheduledPodAutoscaler.
+type ScheduledPodAutoscalerSpec struct {
+	// HorizontalPodAutoscalerSpec is HorizontalPodAutoscaler v2beta2 API spec.
+	// ref: https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.19/#horizontalpodautoscaler-v2beta2-autoscaling
+	// +kubebuilder:validation:Required
+	HorizontalPodAutoscalerSpec autoscalingv2beta2.HorizontalPodAutoscalerSpec `json:"horizontalPodAutoscalerSpec"`
+
+	// MinimumHoldDuration is the minimum time to hold the min/max replicas of the HPA once they have been changed.
+	// The min/max replicas are not lowered again until this duration has elapsed since the last scale.
+	// e.g. "10m", "1h"
+	// +optional
+	MinimumHoldDuration *metav1.Duration `json:"minimumHoldDuration,omitempty"`
+
+	// AdoptionPolicy is the policy for the pre-existing HPA with the same name
+	// that is not controlled by the ScheduledPodAutoscaler, represented by "Adopt", "Fail", "Ignore".
+	// Adopt takes the ownership of the HPA and records its original spec in the
+	// autoscaling.d-kuro.github.io/original-spec annotation of the HPA.
+	// Fail leaves the HPA untouched and reports the ScheduledPodAutoscaler as degraded.
+	// Ignore leaves the HPA untouched without reporting an error.
+	// (default is Fail)
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// HPAName is the name of the HPA generated from the ScheduledPodAutoscaler.
+	// If the name is changed, the HPA with the previous name is deleted.
+	// (default is the name of the ScheduledPodAutoscaler)
+	// +optional
+	HPAName string `json:"hpaName,omitempty"`
+
+	// HPALabels are the labels propagated to the HPA generated from the ScheduledPodAutoscaler.
+	// +optional
+	HPALabels map[string]string `json:"hpaLabels,omitempty"`
+
+	// HPAAnnotations are the annotations propagated to the HPA generated from the ScheduledPodAutoscaler.
+	// +optional
+	HPAAnnotations map[string]string `json:"hpaAnnotations,omitempty"`
+
+	// DeletionPolicy is the policy for the HPA when the ScheduledPodAutoscaler is deleted,
+	// represented by "Delete", "Orphan", "OrphanAtBaseline".
+	// Delete deletes the HPA with the ScheduledPodAutoscaler.
+	// Orphan leaves the HPA as it is.
+	// OrphanAtBaseline leaves the HPA after restoring the spec of the HPA to HorizontalPodAutoscalerSpec,
+	// that is, the spec without any scheduled scaling.
+	// The policies other than Delete are enforced by a finalizer of the ScheduledPodAutoscaler.
+	// (default is Delete)
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DriftPolicy is the policy for the HPA changed manually from the spec applied by the controller,
+	// e.g. by kubectl edit, represented by "Revert", "RespectUntilNextTransition", "Pause".
+	// Revert overwrites the manual changes with the desired spec.
+	// RespectUntilNextTransition keeps the manual changes until the desired spec changes,
+	// e.g. at the start or end of a schedule.
+	// Pause stops updating the HPA until the manual changes are reverted or the
+	// autoscaling.d-kuro.github.io/last-applied-spec annotation of the HPA is removed.
+	// (default is Revert)
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// DriftPolicy is the policy for the HPA changed manually.
+// +kubebuilder:validation:Enum=Revert;RespectUntilNextTransition;Pause
+type DriftPolicy string
+
+const (
+	DriftPolicyRevert                     DriftPolicy = "Revert"
+	DriftPolicyRespectUntilNextTransition DriftPolicy = "RespectUntilNextTransition"
+	DriftPolicyPause                      DriftPolicy = "Pause"
+)
+
+// DeletionPolicy is the policy for the HPA when the ScheduledPodAutoscaler is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan;OrphanAtBaseline
+type DeletionPolicy string
+
+const (
+	DeletionPolicyDelete           DeletionPolicy = "Delete"
+	DeletionPolicyOrphan           DeletionPolicy = "Orphan"
+	DeletionPolicyOrphanAtBaseline DeletionPolicy = "OrphanAtBaseline"
+)
+
+// AdoptionPolicy is the policy for the pre-existing HPA.
+// +kubebuilder:validation:Enum=Adopt;Fail;Ignore
+type AdoptionPolicy string
+
+const (
+	AdoptionPolicyAdopt  AdoptionPolicy = "Adopt"
+	AdoptionPolicyFail   AdoptionPolicy = "Fail"
+	AdoptionPolicyIgnore AdoptionPolicy = "Ignore"
+)
+
+// HPAAdoptionResult is the outcome of the adoption of the pre-existing HPA.
+type HPAAdoptionResult string
+
+const (
+	HPAAdopted  HPAAdoptionResult = "Adopted"
+	HPARejected HPAAdoptionResult = "Rejected"
+	HPAIgnored  HPAAdoptionResult = "Ignored"
+)
+
+// DryRunStatus is the min/max replicas that the controller would set to the HPA in the dry-run mode.
+type DryRunStatus struct {
+	// MinReplicas is the min replicas that would be set to the HPA.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the max replicas that would be set to the HPA.
+	// +optional
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+
+	// Time is the time the min/max replicas were calculated.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	Time metav1.Time `json:"time"`
+}
+
+// HPAAdoptionStatus describes the outcome of the adoption of the pre-existing HPA.
+type HPAAdoptionStatus struct {
+	// Result is the outcome of the adoption represented by "Adopted", "Rejected", "Ignored".
+	Result HPAAdoptionResult `json:"result"`
+
+	// Time is the time the outcome was observed.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	Time metav1.Time `json:"time"`
+
+	// Message is a human readable message about the outcome.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ScheduledPodAutoscalerStatus defines the observed state of ScheduledPodAutoscaler.
+type ScheduledPodAutoscalerStatus struct {
+	// Conditions represent the latest available observations of the ScheduledPodAutoscaler's state.
+	// Supported condition types are "Ready", "Active" and "Degraded".
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// CurrentMinReplicas is the min replicas of the HPA currently set by the controller.
+	// +optional
+	CurrentMinReplicas *int32 `json:"currentMinReplicas,omitempty"`
+
+	// CurrentMaxReplicas is the max replicas of the HPA currently set by the controller.
+	// +optional
+	CurrentMaxReplicas int32 `json:"currentMaxReplicas,omitempty"`
+
+	// ActiveSchedules is the list of names of the schedules that are currently active.
+	// +optional
+	ActiveSchedules []string `json:"activeSchedules,omitempty"`
+
+	// EffectiveSchedule is the name of the schedule whose replicas are applied to the HPA.
+	// If there is more than one active schedule, it is the schedule that provides the min replicas.
+	// +optional
+	EffectiveSchedule string `json:"effectiveSchedule,omitempty"`
+
+	// LastScaleTime is the last time the min/max replicas of the HPA were changed by the controller.
+	// It is used to calculate the minimum hold duration.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// HPAAdoption is the outcome of the adoption of the pre-existing HPA that was not created by the controller.
+	// +optional
+	HPAAdoption *HPAAdoptionStatus `json:"hpaAdoption,omitempty"`
+
+	// HPAName is the name of the HPA currently managed by the controller.
+	// +optional
+	HPAName string `json:"hpaName,omitempty"`
+
+	// DryRun is the min/max replicas that would be set to the HPA if the controller is in the dry-run mode.
+	// +optional
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=spa
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="MINPODS",type=integer,JSONPath=`.spec.horizontalPodAutoscalerSpec.minReplicas`,priority=0
+// +kubebuilder:printcolumn:name="MAXPODS",type=integer,JSONPath=`.spec.horizontalPodAutoscalerSpec.maxReplicas`,priority=0
+// +kubebuilder:printcolumn:name="CURRENTMINPODS",type=integer,JSONPath=`.status.currentMinReplicas`,priority=0
+// +kubebuilder:printcolumn:name="CURRENTMAXPODS",type=integer,JSONPath=`.status.currentMaxReplicas`,priority=0
+// +kubebuilder:printcolumn:name="SCHEDULE",type=string,JSONPath=`.status.effectiveSchedule`,priority=0
+// +kubebuilder:printcolumn:name="ACTIVESCHEDULES",type=string,JSONPath=`.status.activeSchedules`,priority=1
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=0
+// +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",priority=0
+
+// ScheduledPodAutoscaler is the Schema for the scheduledpodautoscalers API.
+type ScheduledPodAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScheduledPodAutoscalerSpec   `json:"spec,omitempty"`
+	Status ScheduledPodAutoscalerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScheduledPodAutoscalerList contains a list of ScheduledPodAutoscaler.
+type ScheduledPodAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScheduledPodAutoscaler `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScheduledPodAutoscaler{}, &ScheduledPodAutoscalerList{})
+}
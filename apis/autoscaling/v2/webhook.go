@@ -0,0 +1,15 @@
+package v2
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SetupWebhookWithManager registers the conversion webhook of the Schedule.
+func (s *Schedule) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(s).Complete()
+}
+
+// SetupWebhookWithManager registers the conversion webhook of the ScheduledPodAutoscaler.
+func (s *ScheduledPodAutoscaler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(s).Complete()
+}
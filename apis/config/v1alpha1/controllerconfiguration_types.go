@@ -0,0 +1,122 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+)
+
+// Feature gates of the controller manager.
+const (
+	// FeatureServerSideApply writes the HPAs and the statuses with the server-side apply.
+	FeatureServerSideApply = "ServerSideApply"
+	// FeatureMigrateStorageVersion rewrites the stored custom resources in the storage version on startup.
+	FeatureMigrateStorageVersion = "MigrateStorageVersion"
+	// FeatureHPAProtection enables the validating webhook that protects the HPAs controlled by a ScheduledPodAutoscaler.
+	FeatureHPAProtection = "HPAProtection"
+)
+
+// +kubebuilder:object:root=true
+
+// ControllerConfiguration is the Schema for the configuration file of the controller manager.
+// The fields not set in the file keep the defaults of the command-line flags,
+// and the command-line flags take precedence over the file.
+type ControllerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ControllerManagerConfigurationSpec is the configuration of the controller-runtime manager,
+	// that is, the sync period, the leader election, the cache namespace, the metrics, the health probes
+	// and the webhook server.
+	cfg.ControllerManagerConfigurationSpec `json:",inline"`
+
+	// EnableWebhook enables the admission webhooks.
+	// +optional
+	EnableWebhook *bool `json:"enableWebhook,omitempty"`
+
+	// DefaultTimeZone is the time zone set by the webhook to the Schedule whose timeZone is not specified.
+	// +optional
+	DefaultTimeZone string `json:"defaultTimeZone,omitempty"`
+
+	// OverlapWarningWeeks is the number of weeks from now in which the webhook warns the overlaps
+	// between the schedules. Setting 0 disables the warnings.
+	// +optional
+	OverlapWarningWeeks *int `json:"overlapWarningWeeks,omitempty"`
+
+	// HPAProtectionAllowedUsers is the list of the users allowed to change the HPAs protected by the webhook.
+	// +optional
+	HPAProtectionAllowedUsers []string `json:"hpaProtectionAllowedUsers,omitempty"`
+
+	// MaxRequeueAfter is the upper limit of the time until a ScheduledPodAutoscaler is reconciled again
+	// without any events. If it is not set, a ScheduledPodAutoscaler is reconciled at its next transition
+	// and resynced every sync period.
+	// +optional
+	MaxRequeueAfter *metav1.Duration `json:"maxRequeueAfter,omitempty"`
+
+	// Concurrency is the maximum number of the resources reconciled concurrently by each controller.
+	// +optional
+	Concurrency ControllerConcurrency `json:"concurrency,omitempty"`
+
+	// Namespaces is the list of the namespaces watched by the controller.
+	// All namespaces are watched if it is empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ExcludeNamespaces is the list of the namespaces ignored by the controller.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// DryRun calculates the min/max replicas and records them, but never writes the HPAs.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// Sharding splits the namespaces among the active replicas instead of the leader election.
+	// +optional
+	Sharding ShardingConfiguration `json:"sharding,omitempty"`
+
+	// FeatureGates enables or disables the features by the name.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// ControllerConcurrency is the maximum number of the resources reconciled concurrently by each controller.
+type ControllerConcurrency struct {
+	// ScheduledPodAutoscaler is the maximum number of ScheduledPodAutoscalers reconciled concurrently.
+	// +optional
+	ScheduledPodAutoscaler *int `json:"scheduledPodAutoscaler,omitempty"`
+
+	// Schedule is the maximum number of Schedules reconciled concurrently.
+	// +optional
+	Schedule *int `json:"schedule,omitempty"`
+}
+
+// ShardingConfiguration is the configuration of the namespaces split among the active replicas.
+type ShardingConfiguration struct {
+	// Enable splits the namespaces among the active replicas by the hash of the namespaces.
+	// It cannot be used with the leader election.
+	// +optional
+	Enable *bool `json:"enable,omitempty"`
+
+	// LeaseDuration is the duration after which the namespaces of a replica that does not renew its Lease
+	// are taken over by the others.
+	// +optional
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ControllerConfiguration{})
+}
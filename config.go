@@ -0,0 +1,193 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+
+	configv1alpha1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/config/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// featureGateFlags is the command-line flags of the feature gates of the configuration file.
+var featureGateFlags = map[string]string{
+	configv1alpha1.FeatureServerSideApply:       "server-side-apply",
+	configv1alpha1.FeatureMigrateStorageVersion: "migrate-storage-version",
+	configv1alpha1.FeatureHPAProtection:         "enable-hpa-protection",
+}
+
+// loadConfigFile reads the configuration file and sets its values to the flags not set on the command line,
+// so that the command-line flags take precedence over the file.
+func loadConfigFile(path string, fs *flag.FlagSet) (*configv1alpha1.ControllerConfiguration, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &configv1alpha1.ControllerConfiguration{}
+	codecs := serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+
+	if err := runtime.DecodeInto(codecs.UniversalDecoder(configv1alpha1.GroupVersion), content, config); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", path, err)
+	}
+
+	values, err := configFlagValues(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+
+	visited := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+
+	for name, value := range values {
+		if visited[name] {
+			continue
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid configuration file %s: %s: %w", path, name, err)
+		}
+	}
+
+	return config, nil
+}
+
+// configFlagValues returns the values of the command-line flags set in the configuration file.
+// The cacheNamespace of the manager configuration and the namespaces are the same flag,
+// so the file setting both of them is rejected.
+func configFlagValues(config *configv1alpha1.ControllerConfiguration) (map[string]string, error) {
+	if config.CacheNamespace != "" && len(config.Namespaces) > 0 {
+		return nil, fmt.Errorf("cacheNamespace and namespaces cannot be set together, use namespaces")
+	}
+
+	values := make(map[string]string)
+
+	setString := func(name string, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+
+	setList := func(name string, value []string) {
+		if len(value) > 0 {
+			values[name] = strings.Join(value, ",")
+		}
+	}
+
+	setDuration := func(name string, value *metav1.Duration) {
+		if value != nil {
+			values[name] = value.Duration.String()
+		}
+	}
+
+	setInt := func(name string, value *int) {
+		if value != nil {
+			values[name] = strconv.Itoa(*value)
+		}
+	}
+
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			values[name] = strconv.FormatBool(*value)
+		}
+	}
+
+	setDuration("sync-period", config.SyncPeriod)
+	setString("metrics-addr", config.Metrics.BindAddress)
+	setString("probe-addr", config.Health.HealthProbeBindAddress)
+	setString("namespaces", config.CacheNamespace)
+	setList("namespaces", config.Namespaces)
+	setList("exclude-namespaces", config.ExcludeNamespaces)
+	setBool("enable-webhook", config.EnableWebhook)
+	setString("default-time-zone", config.DefaultTimeZone)
+	setInt("overlap-warning-weeks", config.OverlapWarningWeeks)
+	setList("hpa-protection-allowed-users", config.HPAProtectionAllowedUsers)
+	setDuration("max-requeue-after", config.MaxRequeueAfter)
+	setInt("scheduled-pod-autoscaler-concurrency", config.Concurrency.ScheduledPodAutoscaler)
+	setInt("schedule-concurrency", config.Concurrency.Schedule)
+	setBool("dry-run", config.DryRun)
+	setBool("enable-sharding", config.Sharding.Enable)
+	setDuration("sharding-lease-duration", config.Sharding.LeaseDuration)
+
+	if leaderElection := config.LeaderElection; leaderElection != nil {
+		setBool("enable-leader-election", leaderElection.LeaderElect)
+		setString("leader-election-id", leaderElection.ResourceName)
+		setString("leader-election-namespace", leaderElection.ResourceNamespace)
+		setString("leader-election-resource-lock", leaderElection.ResourceLock)
+
+		if !reflect.DeepEqual(leaderElection.LeaseDuration, metav1.Duration{}) {
+			setDuration("leader-election-lease-duration", &leaderElection.LeaseDuration)
+		}
+
+		if !reflect.DeepEqual(leaderElection.RenewDeadline, metav1.Duration{}) {
+			setDuration("leader-election-renew-deadline", &leaderElection.RenewDeadline)
+		}
+
+		if !reflect.DeepEqual(leaderElection.RetryPeriod, metav1.Duration{}) {
+			setDuration("leader-election-retry-period", &leaderElection.RetryPeriod)
+		}
+	}
+
+	for feature, enabled := range config.FeatureGates {
+		name, found := featureGateFlags[feature]
+		if !found {
+			return nil, fmt.Errorf("unknown feature gate %q", feature)
+		}
+
+		values[name] = strconv.FormatBool(enabled)
+	}
+
+	return values, nil
+}
+
+// applyConfigFileOptions sets the options of the manager in the configuration file
+// that do not have the command-line flags.
+func applyConfigFileOptions(config *configv1alpha1.ControllerConfiguration, options *ctrl.Options) {
+	if config.GracefulShutdownTimeout != nil {
+		options.GracefulShutdownTimeout = &config.GracefulShutdownTimeout.Duration
+	}
+
+	if config.Health.ReadinessEndpointName != "" {
+		options.ReadinessEndpointName = config.Health.ReadinessEndpointName
+	}
+
+	if config.Health.LivenessEndpointName != "" {
+		options.LivenessEndpointName = config.Health.LivenessEndpointName
+	}
+
+	if config.Webhook.Port != nil {
+		options.Port = *config.Webhook.Port
+	}
+
+	if config.Webhook.Host != "" {
+		options.Host = config.Webhook.Host
+	}
+
+	if config.Webhook.CertDir != "" {
+		options.CertDir = config.Webhook.CertDir
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	configv1alpha1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/config/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	componentbaseconfig "k8s.io/component-base/config/v1alpha1"
+	cfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+)
+
+func TestConfigFlagValues(t *testing.T) {
+	enabled := true
+	concurrency := 4
+
+	tests := []struct {
+		name     string
+		config   configv1alpha1.ControllerConfiguration
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			config:   configv1alpha1.ControllerConfiguration{},
+			expected: map[string]string{},
+		},
+		{
+			name: "options",
+			config: configv1alpha1.ControllerConfiguration{
+				ControllerManagerConfigurationSpec: cfg.ControllerManagerConfigurationSpec{
+					SyncPeriod: &metav1.Duration{Duration: 2 * time.Hour},
+					Metrics:    cfg.ControllerMetrics{BindAddress: ":8081"},
+				},
+				EnableWebhook:     &enabled,
+				DefaultTimeZone:   "Asia/Tokyo",
+				Namespaces:        []string{"team-a", "team-b"},
+				ExcludeNamespaces: []string{"kube-system"},
+				MaxRequeueAfter:   &metav1.Duration{Duration: 10 * time.Minute},
+				Concurrency:       configv1alpha1.ControllerConcurrency{ScheduledPodAutoscaler: &concurrency},
+			},
+			expected: map[string]string{
+				"sync-period":                          "2h0m0s",
+				"metrics-addr":                         ":8081",
+				"enable-webhook":                       "true",
+				"default-time-zone":                    "Asia/Tokyo",
+				"namespaces":                           "team-a,team-b",
+				"exclude-namespaces":                   "kube-system",
+				"max-requeue-after":                    "10m0s",
+				"scheduled-pod-autoscaler-concurrency": "4",
+			},
+		},
+		{
+			name: "cache namespace",
+			config: configv1alpha1.ControllerConfiguration{
+				ControllerManagerConfigurationSpec: cfg.ControllerManagerConfigurationSpec{CacheNamespace: "team-a"},
+			},
+			expected: map[string]string{"namespaces": "team-a"},
+		},
+		{
+			name: "cache namespace and namespaces",
+			config: configv1alpha1.ControllerConfiguration{
+				ControllerManagerConfigurationSpec: cfg.ControllerManagerConfigurationSpec{CacheNamespace: "team-a"},
+				Namespaces:                         []string{"team-b"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "feature gates",
+			config: configv1alpha1.ControllerConfiguration{
+				FeatureGates: map[string]bool{
+					configv1alpha1.FeatureServerSideApply: false,
+					configv1alpha1.FeatureHPAProtection:   true,
+				},
+			},
+			expected: map[string]string{
+				"server-side-apply":     "false",
+				"enable-hpa-protection": "true",
+			},
+		},
+		{
+			name: "unknown feature gate",
+			config: configv1alpha1.ControllerConfiguration{
+				FeatureGates: map[string]bool{"Unknown": true},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := configFlagValues(&tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configFlagValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.expected, values); diff != "" {
+				t.Errorf("flag values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigFlagValuesLeaderElection(t *testing.T) {
+	enabled := true
+
+	tests := []struct {
+		name           string
+		leaderElection *componentbaseconfig.LeaderElectionConfiguration
+		expected       map[string]string
+	}{
+		{
+			name:           "no leader election",
+			leaderElection: nil,
+			expected:       map[string]string{},
+		},
+		{
+			name: "durations",
+			leaderElection: &componentbaseconfig.LeaderElectionConfiguration{
+				LeaderElect:       &enabled,
+				ResourceName:      "spa-leader",
+				ResourceNamespace: "autoscaling",
+				ResourceLock:      "leases",
+				LeaseDuration:     metav1.Duration{Duration: 30 * time.Second},
+				RenewDeadline:     metav1.Duration{Duration: 20 * time.Second},
+				RetryPeriod:       metav1.Duration{Duration: 5 * time.Second},
+			},
+			expected: map[string]string{
+				"enable-leader-election":         "true",
+				"leader-election-id":             "spa-leader",
+				"leader-election-namespace":      "autoscaling",
+				"leader-election-resource-lock":  "leases",
+				"leader-election-lease-duration": "30s",
+				"leader-election-renew-deadline": "20s",
+				"leader-election-retry-period":   "5s",
+			},
+		},
+		{
+			name: "zero durations keep the defaults",
+			leaderElection: &componentbaseconfig.LeaderElectionConfiguration{
+				LeaderElect:   &enabled,
+				LeaseDuration: metav1.Duration{Duration: 30 * time.Second},
+			},
+			expected: map[string]string{
+				"enable-leader-election":         "true",
+				"leader-election-lease-duration": "30s",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			config := &configv1alpha1.ControllerConfiguration{
+				ControllerManagerConfigurationSpec: cfg.ControllerManagerConfigurationSpec{
+					LeaderElection: tt.leaderElection,
+				},
+			}
+
+			values, err := configFlagValues(config)
+			if err != nil {
+				t.Fatalf("configFlagValues() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, values); diff != "" {
+				t.Errorf("flag values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	const content = `apiVersion: config.autoscaling.d-kuro.github.io/v1alpha1
+kind: ControllerConfiguration
+syncPeriod: 2h
+dryRun: true
+namespaces:
+  - team-a
+  - team-b
+`
+
+	tests := []struct {
+		name     string
+		content  string
+		args     []string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:    "file",
+			content: content,
+			expected: map[string]string{
+				"sync-period": "2h0m0s",
+				"dry-run":     "true",
+				"namespaces":  "team-a,team-b",
+			},
+		},
+		{
+			name:    "command-line flags take precedence",
+			content: content,
+			args:    []string{"--dry-run=false", "--namespaces=team-c"},
+			expected: map[string]string{
+				"sync-period": "2h0m0s",
+				"dry-run":     "false",
+				"namespaces":  "team-c",
+			},
+		},
+		{
+			name:    "unknown field",
+			content: content + "unknown: true\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown feature gate",
+			content: content + "featureGates:\n  Unknown: true\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			syncPeriod := fs.Duration("sync-period", time.Hour, "")
+			dryRun := fs.Bool("dry-run", false, "")
+			namespaces := fs.String("namespaces", "", "")
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := loadConfigFile(path, fs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			actual := map[string]string{
+				"sync-period": syncPeriod.String(),
+				"dry-run":     strconv.FormatBool(*dryRun),
+				"namespaces":  *namespaces,
+			}
+
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Errorf("flag values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
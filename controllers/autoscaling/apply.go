@@ -0,0 +1,278 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// FieldManager is the field manager of the server-side apply by the controllers.
+const FieldManager = "scheduled-pod-autoscaler"
+
+// legacyFieldManager is the field manager of the updates by the controllers before the server-side apply,
+// that is, the default field manager derived from the name of the binary.
+const legacyFieldManager = "manager"
+
+// updateStatus changes the status of the object with the mutate function, and writes it if it has been changed.
+// On conflict, the object is read again with the reader and the mutate function is applied to the fresh object,
+// so that the concurrent changes are not lost. The reader defaults to the client.
+// It returns true if the status has been changed.
+func updateStatus(ctx context.Context, c client.Client, reader client.Reader, fieldManager string,
+	obj client.Object, mutate func() bool) (bool, error) {
+	if reader == nil {
+		reader = c
+	}
+
+	key := client.ObjectKeyFromObject(obj)
+	changed := false
+	attempt := 0
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if attempt > 0 {
+			// the object is cleared first, since the fields omitted in the response are not cleared by the decoding,
+			// e.g. the fields changed by the failed attempt.
+			value := reflect.ValueOf(obj).Elem()
+			value.Set(reflect.Zero(value.Type()))
+
+			if err := reader.Get(ctx, key, obj); err != nil {
+				return err
+			}
+		}
+
+		attempt++
+
+		if changed = mutate(); !changed {
+			return nil
+		}
+
+		return writeStatus(ctx, c, fieldManager, obj)
+	})
+	if err != nil {
+		kind := "unknown"
+		if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+			kind = gvk.Kind
+		}
+
+		statusWriteFailureCounter.WithLabelValues(kind).Inc()
+	}
+
+	return changed, err
+}
+
+// updateScheduledPodAutoscalerStatus updates the ScheduledPodAutoscaler status if it differs from the old status.
+// Only the fields changed from the old status are written, so that on conflict the concurrent changes
+// of the other fields in the fresh ScheduledPodAutoscaler are kept.
+func (r *ScheduledPodAutoscalerReconciler) updateScheduledPodAutoscalerStatus(ctx context.Context,
+	log logr.Logger, spa *autoscalingv1.ScheduledPodAutoscaler, old *autoscalingv1.ScheduledPodAutoscalerStatus) error {
+	desired := spa.Status.DeepCopy()
+	spa.Status = *old.DeepCopy()
+
+	if _, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, spa, func() bool {
+		merged := spa.Status.DeepCopy()
+		mergeScheduledPodAutoscalerStatus(merged, old, desired.DeepCopy())
+
+		if equality.Semantic.DeepEqual(&spa.Status, merged) {
+			return false
+		}
+
+		spa.Status = *merged
+
+		return true
+	}); err != nil {
+		log.Error(err, "unable to update ScheduledPodAutoscaler status", "scheduledPodAutoscaler", spa)
+
+		return err
+	}
+
+	return nil
+}
+
+// mergeScheduledPodAutoscalerStatus applies the fields of the desired status changed from the old status
+// to the status. The conditions are merged per type.
+func mergeScheduledPodAutoscalerStatus(status *autoscalingv1.ScheduledPodAutoscalerStatus,
+	old *autoscalingv1.ScheduledPodAutoscalerStatus, desired *autoscalingv1.ScheduledPodAutoscalerStatus) {
+	changed := func(old interface{}, desired interface{}) bool {
+		return !equality.Semantic.DeepEqual(old, desired)
+	}
+
+	if changed(old.LastTransitionTime, desired.LastTransitionTime) {
+		status.LastTransitionTime = desired.LastTransitionTime
+	}
+
+	if changed(old.Condition, desired.Condition) {
+		status.Condition = desired.Condition
+	}
+
+	if changed(old.ObservedGeneration, desired.ObservedGeneration) {
+		status.ObservedGeneration = desired.ObservedGeneration
+	}
+
+	if changed(old.CurrentMinReplicas, desired.CurrentMinReplicas) {
+		status.CurrentMinReplicas = desired.CurrentMinReplicas
+	}
+
+	if changed(old.CurrentMaxReplicas, desired.CurrentMaxReplicas) {
+		status.CurrentMaxReplicas = desired.CurrentMaxReplicas
+	}
+
+	if changed(old.ActiveSchedules, desired.ActiveSchedules) {
+		status.ActiveSchedules = desired.ActiveSchedules
+	}
+
+	if changed(old.EffectiveSchedule, desired.EffectiveSchedule) {
+		status.EffectiveSchedule = desired.EffectiveSchedule
+	}
+
+	if changed(old.LastScaleTime, desired.LastScaleTime) {
+		status.LastScaleTime = desired.LastScaleTime
+	}
+
+	if changed(old.HPAAdoption, desired.HPAAdoption) {
+		status.HPAAdoption = desired.HPAAdoption
+	}
+
+	if changed(old.HPAName, desired.HPAName) {
+		status.HPAName = desired.HPAName
+	}
+
+	if changed(old.DryRun, desired.DryRun) {
+		status.DryRun = desired.DryRun
+	}
+
+	mergeConditions(&status.Conditions, old.Conditions, desired.Conditions)
+}
+
+// mergeConditions applies the conditions of the desired conditions changed from the old conditions,
+// and removes the conditions removed from the old conditions.
+func mergeConditions(conditions *[]metav1.Condition, old []metav1.Condition, desired []metav1.Condition) {
+	for _, condition := range desired {
+		if current := meta.FindStatusCondition(old, condition.Type); current != nil &&
+			equality.Semantic.DeepEqual(*current, condition) {
+			continue
+		}
+
+		if current := meta.FindStatusCondition(*conditions, condition.Type); current != nil {
+			*current = condition
+
+			continue
+		}
+
+		*conditions = append(*conditions, condition)
+	}
+
+	for _, condition := range old {
+		if meta.FindStatusCondition(desired, condition.Type) == nil {
+			meta.RemoveStatusCondition(conditions, condition.Type)
+		}
+	}
+}
+
+// writeStatus writes the status of the object with the server-side apply if the field manager is set,
+// or updates the status otherwise. The object is updated with the response.
+func writeStatus(ctx context.Context, c client.Client, fieldManager string, obj client.Object) error {
+	if fieldManager == "" {
+		return c.Status().Update(ctx, obj)
+	}
+
+	if err := dropLegacyStatusManagers(ctx, c, fieldManager, obj); err != nil {
+		return err
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	patch := &unstructured.Unstructured{Object: map[string]interface{}{"status": content["status"]}}
+	patch.SetGroupVersionKind(gvk)
+	patch.SetName(obj.GetName())
+	patch.SetNamespace(obj.GetNamespace())
+	// the resource version keeps the optimistic concurrency of the status written by the controllers.
+	patch.SetResourceVersion(obj.GetResourceVersion())
+
+	if err := c.Status().Patch(ctx, patch, client.Apply,
+		client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(patch.Object, obj)
+}
+
+// dropLegacyStatusManagers removes the managed fields entries of the status written by the update
+// by the controller before the server-side apply was introduced.
+// The fields owned by them would not be removed when the server-side apply omits them.
+// The entries of the other managers are kept, since the other tools may write the status.
+func dropLegacyStatusManagers(ctx context.Context, c client.Client, fieldManager string, obj client.Object) error {
+	managedFields := obj.GetManagedFields()
+	kept := make([]metav1.ManagedFieldsEntry, 0, len(managedFields))
+
+	for _, entry := range managedFields {
+		if entry.Operation == metav1.ManagedFieldsOperationUpdate && entry.Manager == legacyFieldManager &&
+			entry.Manager != fieldManager && ownsOnlyStatus(entry) {
+			continue
+		}
+
+		kept = append(kept, entry)
+	}
+
+	// the empty managed fields are ignored by the API server.
+	if len(kept) == len(managedFields) || len(kept) == 0 {
+		return nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	obj.SetManagedFields(kept)
+
+	return c.Patch(ctx, obj, patch)
+}
+
+func ownsOnlyStatus(entry metav1.ManagedFieldsEntry) bool {
+	if entry.FieldsV1 == nil {
+		return false
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+		return false
+	}
+
+	for key := range fields {
+		if key != "f:status" {
+			return false
+		}
+	}
+
+	return len(fields) > 0
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/d-kuro/scheduled-pod-autoscaler/controllers/autoscaling/internal/testutil"
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	prometheustestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// conflictingClient fails to write all the statuses with the conflict.
+type conflictingClient struct {
+	client.Client
+}
+
+func (c *conflictingClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: c.Client.Status()}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+}
+
+func (w *conflictingStatusWriter) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return apierrors.NewConflict(autoscalingv1.GroupVersion.WithResource("scheduledpodautoscalers").GroupResource(),
+		obj.GetName(), nil)
+}
+
+var _ = ginkgo.Describe("server-side apply", func() {
+	managers := func(obj client.Object) []string {
+		var names []string
+		for _, entry := range obj.GetManagedFields() {
+			names = append(names, entry.Manager)
+		}
+
+		return names
+	}
+
+	ginkgo.It("should keep the HPA fields owned by the other managers", func() {
+		const (
+			name              = "apply-co-ownership-test"
+			otherManager      = "other-tool"
+			scheduledReplicas = 10
+		)
+
+		ctx := context.Background()
+
+		other := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"name":       name,
+				},
+				"maxReplicas": int64(defaultSPAMaxReplicas),
+				"metrics": []interface{}{
+					map[string]interface{}{
+						"type": "Resource",
+						"resource": map[string]interface{}{
+							"name": "memory",
+							"target": map[string]interface{}{
+								"type":               "Utilization",
+								"averageUtilization": int64(80),
+							},
+						},
+					},
+				},
+				"behavior": map[string]interface{}{
+					"scaleDown": map[string]interface{}{
+						"stabilizationWindowSeconds": int64(60),
+					},
+				},
+			},
+		}}
+		other.SetGroupVersionKind(hpav2beta2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"))
+		other.SetName(name)
+		other.SetNamespace(defaultTestNamespace)
+		other.SetLabels(map[string]string{"team": "platform"})
+
+		err := k8sClient.Patch(ctx, other, client.Apply, client.FieldOwner(otherManager))
+		gomega.Expect(err).Should(gomega.Succeed())
+
+		r := &ScheduledPodAutoscalerReconciler{
+			Client:       k8sClient,
+			Scheme:       scheme.Scheme,
+			FieldManager: FieldManager,
+		}
+		spa := newScheduledPodAutoscaler(name)
+
+		// the existing HPA is updated with the spec of the ScheduledPodAutoscaler and the scheduled replicas.
+		hpa := &hpav2beta2.HorizontalPodAutoscaler{}
+		gomega.Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, hpa)
+		}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+		spa.Spec.HorizontalPodAutoscalerSpec.DeepCopyInto(&hpa.Spec)
+		hpa.Spec.MaxReplicas = scheduledReplicas
+
+		err = r.applyHPAObject(ctx, spa, hpa)
+		gomega.Expect(err).Should(gomega.Succeed())
+
+		// the cache of the client is synced with the applied HPA by the resource version.
+		var applied hpav2beta2.HorizontalPodAutoscaler
+		gomega.Eventually(func() (string, error) {
+			err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &applied)
+
+			return applied.ResourceVersion, err
+		}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Equal(hpa.ResourceVersion))
+
+		gomega.Expect(applied.Labels).To(gomega.HaveKeyWithValue("team", "platform"))
+		gomega.Expect(applied.Spec.Behavior).NotTo(gomega.BeNil())
+		gomega.Expect(applied.Spec.Behavior.ScaleDown).NotTo(gomega.BeNil())
+		gomega.Expect(*applied.Spec.Behavior.ScaleDown.StabilizationWindowSeconds).To(gomega.Equal(int32(60)))
+		gomega.Expect(applied.Spec.Metrics).To(gomega.HaveLen(1))
+		gomega.Expect(string(applied.Spec.Metrics[0].Resource.Name)).To(gomega.Equal("memory"))
+		gomega.Expect(*applied.Spec.MinReplicas).To(gomega.Equal(int32(defaultSPAMinReplicas)))
+		gomega.Expect(applied.Spec.MaxReplicas).To(gomega.Equal(int32(scheduledReplicas)))
+		gomega.Expect(managers(&applied)).To(gomega.ContainElements(otherManager, FieldManager))
+	})
+
+	ginkgo.It("should drop the status managers of the legacy updates", func() {
+		const (
+			name          = "apply-legacy-status-test"
+			legacyManager = legacyFieldManager
+			editManager   = "kubectl-edit"
+		)
+
+		ctx := context.Background()
+		spa := newScheduledPodAutoscaler(name)
+
+		err := k8sClient.Create(ctx, spa)
+		gomega.Expect(err).Should(gomega.Succeed())
+
+		gomega.Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(spa), spa); err != nil {
+				return err
+			}
+
+			spa.Labels = map[string]string{"team": "platform"}
+
+			return k8sClient.Update(ctx, spa, client.FieldOwner(editManager))
+		}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+		gomega.Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(spa), spa); err != nil {
+				return err
+			}
+
+			spa.Status.Condition = autoscalingv1.ScheduledPodAutoscalerAvailable
+
+			return k8sClient.Status().Update(ctx, spa, client.FieldOwner(legacyManager))
+		}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+		gomega.Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(spa), spa); err != nil {
+				return err
+			}
+
+			return writeStatus(ctx, k8sClient, FieldManager, spa)
+		}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+		gomega.Eventually(func() ([]string, error) {
+			var written autoscalingv1.ScheduledPodAutoscaler
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(spa), &written)
+
+			return managers(&written), err
+		}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.And(
+			gomega.ContainElements(editManager, FieldManager),
+			gomega.Not(gomega.ContainElement(legacyManager)),
+		))
+	})
+})
+
+var _ = ginkgo.Describe("dropLegacyStatusManagers", func() {
+	ginkgo.It("should drop only the legacy status manager of the controller", func() {
+		ctx := context.Background()
+		testScheme := runtime.NewScheme()
+		gomega.Expect(autoscalingv1.AddToScheme(testScheme)).To(gomega.Succeed())
+
+		statusFields := &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:condition":{}}}`)}
+		metadataFields := &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{}}}`)}
+
+		spa := newScheduledPodAutoscaler("drop-legacy-status-managers-test")
+		spa.ManagedFields = []metav1.ManagedFieldsEntry{
+			{Manager: legacyFieldManager, Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: statusFields},
+			{Manager: "other-controller", Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: statusFields},
+			{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, FieldsV1: metadataFields},
+			{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply, FieldsV1: statusFields},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(spa).Build()
+		gomega.Expect(dropLegacyStatusManagers(ctx, c, FieldManager, spa)).To(gomega.Succeed())
+
+		var dropped autoscalingv1.ScheduledPodAutoscaler
+		gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(spa), &dropped)).To(gomega.Succeed())
+
+		var names []string
+		for _, entry := range dropped.ManagedFields {
+			names = append(names, entry.Manager)
+		}
+
+		gomega.Expect(names).To(gomega.Equal([]string{"other-controller", "kubectl-edit", FieldManager}))
+	})
+})
+
+var _ = ginkgo.Describe("updateStatus", func() {
+	newClient := func(objs ...client.Object) client.Client {
+		scheme := runtime.NewScheme()
+		gomega.Expect(autoscalingv1.AddToScheme(scheme)).To(gomega.Succeed())
+
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	ginkgo.It("should retry on conflict with the fresh object and keep the concurrent changes", func() {
+		ctx := context.Background()
+		c := newClient(newScheduledPodAutoscaler("update-status-conflict-test"))
+
+		var stale autoscalingv1.ScheduledPodAutoscaler
+		gomega.Expect(c.Get(ctx, client.ObjectKey{Name: "update-status-conflict-test", Namespace: defaultTestNamespace},
+			&stale)).To(gomega.Succeed())
+
+		// the concurrent write makes the resource version of the stale object conflict.
+		concurrent := stale.DeepCopy()
+		concurrent.Status.HPAName = "concurrent"
+		gomega.Expect(c.Status().Update(ctx, concurrent)).To(gomega.Succeed())
+
+		r := &ScheduledPodAutoscalerReconciler{Client: c}
+		old := stale.Status.DeepCopy()
+		stale.Status.CurrentMaxReplicas = 10
+
+		gomega.Expect(r.updateScheduledPodAutoscalerStatus(ctx, logr.Discard(), &stale, old)).To(gomega.Succeed())
+
+		var written autoscalingv1.ScheduledPodAutoscaler
+		gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(&stale), &written)).To(gomega.Succeed())
+		gomega.Expect(written.Status.HPAName).To(gomega.Equal("concurrent"))
+		gomega.Expect(written.Status.CurrentMaxReplicas).To(gomega.Equal(int32(10)))
+	})
+
+	ginkgo.It("should count the failures to write the status", func() {
+		ctx := context.Background()
+		spa := newScheduledPodAutoscaler("update-status-failure-test")
+		c := &conflictingClient{Client: newClient(spa)}
+		failures := prometheustestutil.ToFloat64(statusWriteFailureCounter.WithLabelValues("ScheduledPodAutoscaler"))
+
+		updated, err := updateStatus(ctx, c, nil, "", spa, func() bool {
+			spa.Status.CurrentMaxReplicas = 10
+
+			return true
+		})
+		gomega.Expect(apierrors.IsConflict(err)).To(gomega.BeTrue())
+		gomega.Expect(updated).To(gomega.BeTrue())
+		gomega.Expect(prometheustestutil.ToFloat64(statusWriteFailureCounter.WithLabelValues("ScheduledPodAutoscaler"))).
+			To(gomega.Equal(failures + 1))
+	})
+})
+
+var _ = ginkgo.Describe("mergeScheduledPodAutoscalerStatus", func() {
+	condition := func(conditionType string, status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, Reason: reason}
+	}
+
+	ginkgo.It("should apply only the changed fields and merge the conditions per type", func() {
+		old := &autoscalingv1.ScheduledPodAutoscalerStatus{
+			CurrentMaxReplicas: 3,
+			EffectiveSchedule:  "weekday",
+			Conditions: []metav1.Condition{
+				condition(autoscalingv1.ConditionReady, metav1.ConditionTrue, autoscalingv1.ReasonReconciled),
+				condition(autoscalingv1.ConditionActive, metav1.ConditionTrue, autoscalingv1.ReasonScheduleActive),
+			},
+		}
+
+		desired := old.DeepCopy()
+		desired.CurrentMaxReplicas = 10
+		desired.CurrentMinReplicas = testutil.ToPointerInt32(5)
+		desired.Conditions = []metav1.Condition{
+			condition(autoscalingv1.ConditionReady, metav1.ConditionTrue, autoscalingv1.ReasonReconciled),
+			condition(autoscalingv1.ConditionDegraded, metav1.ConditionFalse, autoscalingv1.ReasonReconciled),
+		}
+
+		fresh := old.DeepCopy()
+		fresh.EffectiveSchedule = "weekend"
+		fresh.Conditions[0] = condition(autoscalingv1.ConditionReady, metav1.ConditionFalse, autoscalingv1.ReasonHPAAdoptFailed)
+
+		mergeScheduledPodAutoscalerStatus(fresh, old, desired)
+
+		gomega.Expect(fresh.CurrentMaxReplicas).To(gomega.Equal(int32(10)))
+		gomega.Expect(*fresh.CurrentMinReplicas).To(gomega.Equal(int32(5)))
+		gomega.Expect(fresh.EffectiveSchedule).To(gomega.Equal("weekend"))
+		gomega.Expect(fresh.Conditions).To(gomega.Equal([]metav1.Condition{
+			condition(autoscalingv1.ConditionReady, metav1.ConditionFalse, autoscalingv1.ReasonHPAAdoptFailed),
+			condition(autoscalingv1.ConditionDegraded, metav1.ConditionFalse, autoscalingv1.ReasonReconciled),
+		}))
+	})
+})
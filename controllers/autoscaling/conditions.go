@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setScheduleCondition sets the condition and the corresponding standard conditions to the schedule status.
+// The reason and message are used for the Degraded condition.
+// It returns true if the status is updated.
+func setScheduleCondition(status *autoscalingv1.ScheduleStatus, generation int64,
+	newCondition autoscalingv1.ScheduleConditionType, reason string, message string) bool {
+	newStatus := status.DeepCopy()
+	newStatus.Condition = newCondition
+	newStatus.ObservedGeneration = generation
+
+	if newCondition == autoscalingv1.ScheduleDegraded {
+		setDegradedConditions(&newStatus.Conditions, generation, reason, message)
+	} else {
+		setReadyConditions(&newStatus.Conditions, generation, "The schedule has been reconciled.")
+
+		activeCondition := metav1.Condition{
+			Type:               autoscalingv1.ConditionActive,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             autoscalingv1.ReasonNotScheduled,
+			Message:            "Scheduled scaling is not taking place.",
+		}
+
+		switch newCondition {
+		case autoscalingv1.ScheduleProgressing:
+			activeCondition.Status = metav1.ConditionTrue
+			activeCondition.Reason = autoscalingv1.ReasonScheduled
+			activeCondition.Message = "Scheduled scaling is taking place."
+		case autoscalingv1.ScheduleSuspend:
+			activeCondition.Reason = autoscalingv1.ReasonSuspended
+			activeCondition.Message = "The schedule is suspended."
+		case autoscalingv1.ScheduleCompleted:
+			activeCondition.Reason = autoscalingv1.ReasonCompleted
+			activeCondition.Message = "The schedule has been completed."
+		}
+
+		meta.SetStatusCondition(&newStatus.Conditions, activeCondition)
+	}
+
+	if equality.Semantic.DeepEqual(status, newStatus) {
+		return false
+	}
+
+	if status.Condition != newCondition {
+		newStatus.LastTransitionTime = metav1.Now()
+	}
+
+	*status = *newStatus
+
+	return true
+}
+
+// setScheduledPodAutoscalerCondition sets the condition and the corresponding standard conditions
+// to the ScheduledPodAutoscaler status.
+// The reason and message are used for the Degraded condition.
+// It returns true if the status is updated.
+func setScheduledPodAutoscalerCondition(status *autoscalingv1.ScheduledPodAutoscalerStatus, generation int64,
+	newCondition autoscalingv1.ScheduledPodAutoscalerConditionType, reason string, message string) bool {
+	newStatus := status.DeepCopy()
+	newStatus.Condition = newCondition
+	newStatus.ObservedGeneration = generation
+
+	if newCondition == autoscalingv1.ScheduledPodAutoscalerDegraded {
+		setDegradedConditions(&newStatus.Conditions, generation, reason, message)
+	} else {
+		setReadyConditions(&newStatus.Conditions, generation, "The HPA has been reconciled.")
+
+		activeCondition := metav1.Condition{
+			Type:               autoscalingv1.ConditionActive,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             autoscalingv1.ReasonNoActiveSchedule,
+			Message:            "Scheduled scaling is not taking place.",
+		}
+
+		if len(newStatus.ActiveSchedules) > 0 {
+			activeCondition.Status = metav1.ConditionTrue
+			activeCondition.Reason = autoscalingv1.ReasonScheduleActive
+			activeCondition.Message = "Scheduled scaling is taking place."
+		}
+
+		meta.SetStatusCondition(&newStatus.Conditions, activeCondition)
+	}
+
+	if equality.Semantic.DeepEqual(status, newStatus) {
+		return false
+	}
+
+	if status.Condition != newCondition {
+		newStatus.LastTransitionTime = metav1.Now()
+	}
+
+	*status = *newStatus
+
+	return true
+}
+
+func setReadyConditions(conditions *[]metav1.Condition, generation int64, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               autoscalingv1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             autoscalingv1.ReasonReconciled,
+		Message:            message,
+	})
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               autoscalingv1.ConditionDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             autoscalingv1.ReasonReconciled,
+		Message:            message,
+	})
+}
+
+func setDegradedConditions(conditions *[]metav1.Condition, generation int64, reason string, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               autoscalingv1.ConditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               autoscalingv1.ConditionDegraded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,147 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// needsFinalizer returns true if the deletion policy of the ScheduledPodAutoscaler is enforced by the finalizer.
+// The HPA is deleted by the garbage collector with the Delete policy, so the finalizer is not needed.
+func needsFinalizer(spa *autoscalingv1.ScheduledPodAutoscaler) bool {
+	return spa.Spec.DeletionPolicy == autoscalingv1.DeletionPolicyOrphan ||
+		spa.Spec.DeletionPolicy == autoscalingv1.DeletionPolicyOrphanAtBaseline
+}
+
+// reconcileFinalizer adds the finalizer to the ScheduledPodAutoscaler whose deletion policy needs it,
+// and removes the finalizer from the ScheduledPodAutoscaler whose deletion policy no longer needs it.
+func (r *ScheduledPodAutoscalerReconciler) reconcileFinalizer(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler) error {
+	needs := needsFinalizer(spa)
+	if needs == controllerutil.ContainsFinalizer(spa, autoscalingv1.FinalizerName) {
+		return nil
+	}
+
+	if needs {
+		controllerutil.AddFinalizer(spa, autoscalingv1.FinalizerName)
+	} else {
+		controllerutil.RemoveFinalizer(spa, autoscalingv1.FinalizerName)
+	}
+
+	if err := r.Update(ctx, spa); err != nil {
+		log.Error(err, "unable to update finalizer of ScheduledPodAutoscaler", "scheduledPodAutoscaler", spa)
+
+		return err
+	}
+
+	return nil
+}
+
+// finalize enforces the deletion policy of the ScheduledPodAutoscaler being deleted and removes the finalizer.
+func (r *ScheduledPodAutoscalerReconciler) finalize(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler) error {
+	if !controllerutil.ContainsFinalizer(spa, autoscalingv1.FinalizerName) {
+		return nil
+	}
+
+	if needsFinalizer(spa) {
+		if err := r.orphanHPA(ctx, log, spa); err != nil {
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(spa, autoscalingv1.FinalizerName)
+
+	if err := r.Update(ctx, spa); err != nil {
+		log.Error(err, "unable to remove finalizer of ScheduledPodAutoscaler", "scheduledPodAutoscaler", spa)
+
+		return err
+	}
+
+	return nil
+}
+
+// orphanHPA removes the owner reference of the ScheduledPodAutoscaler from the HPA,
+// so that the HPA is not deleted by the garbage collector.
+// The annotation of the last applied spec is also removed since the HPA is no longer managed by the controller.
+// With the OrphanAtBaseline policy, the spec of the HPA is restored to the baseline spec.
+// In the dry-run mode, the HPA is still orphaned so that it is not deleted by the garbage collector,
+// but the spec is not restored.
+func (r *ScheduledPodAutoscalerReconciler) orphanHPA(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler) error {
+	name := spa.Status.HPAName
+	if name == "" {
+		name = spa.HPAName()
+	}
+
+	var hpa hpav2beta2.HorizontalPodAutoscaler
+	if err := r.getHPA(ctx, types.NamespacedName{Namespace: spa.Namespace, Name: name}, &hpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		log.Error(err, "unable to fetch HPA to orphan", "name", name)
+
+		return err
+	}
+
+	if !metav1.IsControlledBy(&hpa, spa) {
+		return nil
+	}
+
+	var ownerReferences []metav1.OwnerReference
+
+	for _, ref := range hpa.OwnerReferences {
+		if ref.UID != spa.UID {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+
+	hpa.OwnerReferences = ownerReferences
+	delete(hpa.Annotations, autoscalingv1.AnnotationLastAppliedSpec)
+
+	if spa.Spec.DeletionPolicy == autoscalingv1.DeletionPolicyOrphanAtBaseline {
+		if r.isDryRun(spa) {
+			log.Info("skipped restoring HPA to baseline in dry-run mode", "hpa", hpa.Name)
+			r.Recorder.Eventf(spa, corev1.EventTypeNormal, "DryRun",
+				"The HPA %s would be restored to the baseline spec in the dry-run mode.", hpa.Name)
+		} else {
+			spa.Spec.HorizontalPodAutoscalerSpec.DeepCopyInto(&hpa.Spec)
+		}
+	}
+
+	if err := r.updateHPAObject(ctx, &hpa); err != nil {
+		log.Error(err, "unable to orphan HPA", "hpa", hpa)
+
+		return err
+	}
+
+	log.Info("successfully orphan HPA", "hpa", hpa, "deletionPolicy", spa.Spec.DeletionPolicy)
+	r.Recorder.Eventf(spa, corev1.EventTypeNormal, "Orphaned", "The HPA %s was orphaned with the %s policy.",
+		hpa.Name, spa.Spec.DeletionPolicy)
+
+	return nil
+}
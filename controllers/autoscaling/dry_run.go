@@ -0,0 +1,93 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isDryRun returns true if the ScheduledPodAutoscaler must not write the HPA,
+// that is, the reconciler or the ScheduledPodAutoscaler is in the dry-run mode.
+func (r *ScheduledPodAutoscalerReconciler) isDryRun(spa *autoscalingv1.ScheduledPodAutoscaler) bool {
+	return r.DryRun || spa.Annotations[autoscalingv1.AnnotationDryRun] == "true"
+}
+
+// recordDryRun records the min/max replicas that would be set to the HPA in the status and the metrics,
+// and emits an event when they are changed and differ from the HPA.
+func (r *ScheduledPodAutoscalerReconciler) recordDryRun(log logr.Logger, spa *autoscalingv1.ScheduledPodAutoscaler,
+	hpa hpav2beta2.HorizontalPodAutoscaler, desired hpav2beta2.HorizontalPodAutoscalerSpec, now time.Time) {
+	if desired.MinReplicas != nil {
+		dryRunMinReplicasGauge.WithLabelValues(spa.Name, spa.Namespace).Set(float64(*desired.MinReplicas))
+	}
+
+	dryRunMaxReplicasGauge.WithLabelValues(spa.Name, spa.Namespace).Set(float64(desired.MaxReplicas))
+
+	previous := spa.Status.DryRun
+	if previous != nil && equality.Semantic.DeepEqual(previous.MinReplicas, desired.MinReplicas) &&
+		previous.MaxReplicas == desired.MaxReplicas {
+		return
+	}
+
+	dryRun := &autoscalingv1.DryRunStatus{MaxReplicas: desired.MaxReplicas, Time: metav1.Time{Time: now}}
+	if desired.MinReplicas != nil {
+		min := *desired.MinReplicas
+		dryRun.MinReplicas = &min
+	}
+
+	spa.Status.DryRun = dryRun
+
+	if equality.Semantic.DeepEqual(hpa.Spec.MinReplicas, desired.MinReplicas) &&
+		hpa.Spec.MaxReplicas == desired.MaxReplicas {
+		return
+	}
+
+	message := fmt.Sprintf("The HPA %s would be updated to min replicas %s and max replicas %d in the dry-run mode.",
+		hpa.Name, formatReplicas(desired.MinReplicas), desired.MaxReplicas)
+
+	log.Info("skipped updating HPA in dry-run mode", "hpa", hpa.Name,
+		"minReplicas", formatReplicas(desired.MinReplicas), "maxReplicas", desired.MaxReplicas)
+	r.Recorder.Event(spa, corev1.EventTypeNormal, "DryRun", message)
+}
+
+// clearDryRun removes the min/max replicas recorded in the dry-run mode.
+func clearDryRun(spa *autoscalingv1.ScheduledPodAutoscaler) {
+	if spa.Status.DryRun == nil {
+		return
+	}
+
+	spa.Status.DryRun = nil
+
+	dryRunMinReplicasGauge.DeleteLabelValues(spa.Name, spa.Namespace)
+	dryRunMaxReplicasGauge.DeleteLabelValues(spa.Name, spa.Namespace)
+}
+
+func formatReplicas(replicas *int32) string {
+	if replicas == nil {
+		return "<unset>"
+	}
+
+	return strconv.Itoa(int(*replicas))
+}
@@ -0,0 +1,350 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// HPAGroupVersionV2 is the GA version of the HPA API served by Kubernetes 1.23+.
+	HPAGroupVersionV2 = schema.GroupVersion{Group: "autoscaling", Version: "v2"}
+	// HPAGroupVersionV2beta2 is the beta version of the HPA API removed in Kubernetes 1.26.
+	HPAGroupVersionV2beta2 = hpav2beta2.SchemeGroupVersion
+
+	// hpaGroupVersions is the list of the supported HPA API versions in order of preference.
+	hpaGroupVersions = []schema.GroupVersion{HPAGroupVersionV2, HPAGroupVersionV2beta2}
+)
+
+// DetectHPAGroupVersion returns the most preferred HPA API version served by the API server.
+func DetectHPAGroupVersion(client discovery.DiscoveryInterface) (schema.GroupVersion, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return schema.GroupVersion{}, err
+	}
+
+	served := make(map[string]bool)
+
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			served[version.GroupVersion] = true
+		}
+	}
+
+	for _, gv := range hpaGroupVersions {
+		if served[gv.String()] {
+			return gv, nil
+		}
+	}
+
+	return schema.GroupVersion{}, fmt.Errorf("none of the HPA API versions %v is served", hpaGroupVersions)
+}
+
+// hpaGroupVersion returns the HPA API version used by the reconciler.
+// It defaults to autoscaling/v2beta2.
+func (r *ScheduledPodAutoscalerReconciler) hpaGroupVersion() schema.GroupVersion {
+	if r.HPAGroupVersion.Empty() {
+		return HPAGroupVersionV2beta2
+	}
+
+	return r.HPAGroupVersion
+}
+
+// hpaObject returns the object to watch the HPAs with the HPA API version used by the reconciler.
+func (r *ScheduledPodAutoscalerReconciler) hpaObject() client.Object {
+	if r.hpaGroupVersion() == HPAGroupVersionV2beta2 {
+		return &hpav2beta2.HorizontalPodAutoscaler{}
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(r.hpaGroupVersion().WithKind("HorizontalPodAutoscaler"))
+
+	return obj
+}
+
+// getHPA fetches the HPA with the HPA API version used by the reconciler.
+// The autoscaling/v2 HPA has the same schema as autoscaling/v2beta2,
+// so it is handled as the autoscaling/v2beta2 HPA in the reconciler.
+func (r *ScheduledPodAutoscalerReconciler) getHPA(ctx context.Context, key types.NamespacedName,
+	hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	if r.hpaGroupVersion() == HPAGroupVersionV2beta2 {
+		return r.Get(ctx, key, hpa)
+	}
+
+	obj := r.hpaObject()
+	if err := r.Get(ctx, key, obj); err != nil {
+		return err
+	}
+
+	return fromHPAObject(obj, hpa)
+}
+
+// createHPAObject creates the HPA with the HPA API version used by the reconciler.
+func (r *ScheduledPodAutoscalerReconciler) createHPAObject(ctx context.Context,
+	hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	if r.hpaGroupVersion() == HPAGroupVersionV2beta2 {
+		return r.Create(ctx, hpa, &client.CreateOptions{})
+	}
+
+	obj, err := r.toHPAObject(hpa)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Create(ctx, obj, &client.CreateOptions{}); err != nil {
+		return err
+	}
+
+	return fromHPAObject(obj, hpa)
+}
+
+// updateHPAObject updates the HPA with the HPA API version used by the reconciler.
+func (r *ScheduledPodAutoscalerReconciler) updateHPAObject(ctx context.Context,
+	hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	if r.hpaGroupVersion() == HPAGroupVersionV2beta2 {
+		return r.Update(ctx, hpa, &client.UpdateOptions{})
+	}
+
+	obj, err := r.toHPAObject(hpa)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Update(ctx, obj, &client.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	return fromHPAObject(obj, hpa)
+}
+
+// applyHPAObject writes the HPA with the server-side apply if the field manager is set,
+// or creates or updates the HPA otherwise.
+// The whole spec is applied only when the HPA is created. Afterwards, the controller applies
+// only the min/max replicas, the metadata managed by the controller and the owner reference,
+// so that the other tools can co-own the other fields of the spec, e.g. the metrics and the behavior.
+func (r *ScheduledPodAutoscalerReconciler) applyHPAObject(ctx context.Context,
+	spa *autoscalingv1.ScheduledPodAutoscaler, hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	if r.FieldManager == "" {
+		if hpa.ResourceVersion == "" {
+			return r.createHPAObject(ctx, hpa)
+		}
+
+		return r.updateHPAObject(ctx, hpa)
+	}
+
+	spec := map[string]interface{}{"maxReplicas": int64(hpa.Spec.MaxReplicas)}
+	if hpa.Spec.MinReplicas != nil {
+		spec["minReplicas"] = int64(*hpa.Spec.MinReplicas)
+	}
+
+	if hpa.ResourceVersion == "" {
+		var err error
+		if spec, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&hpa.Spec); err != nil {
+			return err
+		}
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(r.hpaGroupVersion().WithKind("HorizontalPodAutoscaler"))
+	obj.SetName(hpa.Name)
+	obj.SetNamespace(hpa.Namespace)
+	obj.SetLabels(appliedHPALabels(spa))
+	obj.SetAnnotations(appliedHPAAnnotations(spa, hpa))
+
+	if owner := metav1.GetControllerOf(hpa); owner != nil {
+		obj.SetOwnerReferences([]metav1.OwnerReference{*owner})
+	}
+
+	if err := r.Patch(ctx, obj, client.Apply, client.FieldOwner(r.FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+
+	return fromHPAObject(obj, hpa)
+}
+
+// appliedHPALabels returns the labels of the HPA owned by the controller.
+func appliedHPALabels(spa *autoscalingv1.ScheduledPodAutoscaler) map[string]string {
+	if len(spa.Spec.HPALabels) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(spa.Spec.HPALabels))
+	for key, value := range spa.Spec.HPALabels {
+		labels[key] = value
+	}
+
+	return labels
+}
+
+// appliedHPAAnnotations returns the annotations of the HPA owned by the controller,
+// that is, the propagated annotations and the annotations managed by the controller.
+func appliedHPAAnnotations(spa *autoscalingv1.ScheduledPodAutoscaler,
+	hpa *hpav2beta2.HorizontalPodAutoscaler) map[string]string {
+	annotations := make(map[string]string, len(spa.Spec.HPAAnnotations)+len(managedHPAAnnotations))
+	for key, value := range spa.Spec.HPAAnnotations {
+		annotations[key] = value
+	}
+
+	for _, key := range managedHPAAnnotations {
+		if value, found := hpa.Annotations[key]; found {
+			annotations[key] = value
+		}
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	return annotations
+}
+
+// managedHPAAnnotations are the annotations of the HPA managed by the controller.
+var managedHPAAnnotations = []string{
+	autoscalingv1.AnnotationOriginalHPASpec,
+	autoscalingv1.AnnotationPropagatedMetadata,
+	autoscalingv1.AnnotationLastAppliedSpec,
+}
+
+// deleteHPAObject deletes the HPA with the HPA API version used by the reconciler.
+func (r *ScheduledPodAutoscalerReconciler) deleteHPAObject(ctx context.Context,
+	hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	if r.hpaGroupVersion() == HPAGroupVersionV2beta2 {
+		return r.Delete(ctx, hpa, &client.DeleteOptions{})
+	}
+
+	obj, err := r.toHPAObject(hpa)
+	if err != nil {
+		return err
+	}
+
+	return r.Delete(ctx, obj, &client.DeleteOptions{})
+}
+
+func (r *ScheduledPodAutoscalerReconciler) toHPAObject(hpa *hpav2beta2.HorizontalPodAutoscaler) (
+	*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(hpa)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(r.hpaGroupVersion().WithKind("HorizontalPodAutoscaler"))
+
+	return obj, nil
+}
+
+func fromHPAObject(obj client.Object, hpa *hpav2beta2.HorizontalPodAutoscaler) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected HPA object type %T", obj)
+	}
+
+	var converted hpav2beta2.HorizontalPodAutoscaler
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &converted); err != nil {
+		return err
+	}
+
+	// the typed object does not carry the API version of the unstructured one.
+	converted.TypeMeta = metav1.TypeMeta{}
+	*hpa = converted
+
+	return nil
+}
+
+// propagatedMetadata is the keys of the labels and annotations propagated to the HPA.
+type propagatedMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// propagateHPAMetadata sets the labels and annotations of the ScheduledPodAutoscaler spec to the HPA,
+// and removes the ones that were propagated previously but are no longer specified.
+func propagateHPAMetadata(spa *autoscalingv1.ScheduledPodAutoscaler, hpa *hpav2beta2.HorizontalPodAutoscaler) {
+	var previous propagatedMetadata
+	if value, found := hpa.Annotations[autoscalingv1.AnnotationPropagatedMetadata]; found {
+		// the broken annotation is overwritten, so the previous keys are left on the HPA.
+		_ = json.Unmarshal([]byte(value), &previous)
+	}
+
+	hpa.Labels = propagateMap(hpa.Labels, spa.Spec.HPALabels, previous.Labels)
+	hpa.Annotations = propagateMap(hpa.Annotations, spa.Spec.HPAAnnotations, previous.Annotations)
+
+	current := propagatedMetadata{
+		Labels:      sortedKeys(spa.Spec.HPALabels),
+		Annotations: sortedKeys(spa.Spec.HPAAnnotations),
+	}
+
+	if len(current.Labels) == 0 && len(current.Annotations) == 0 {
+		delete(hpa.Annotations, autoscalingv1.AnnotationPropagatedMetadata)
+
+		if len(hpa.Annotations) == 0 {
+			hpa.Annotations = nil
+		}
+
+		return
+	}
+
+	// the keys are sorted, so the marshaled value is stable.
+	value, _ := json.Marshal(current)
+	metav1.SetMetaDataAnnotation(&hpa.ObjectMeta, autoscalingv1.AnnotationPropagatedMetadata, string(value))
+}
+
+func propagateMap(dst map[string]string, src map[string]string, previousKeys []string) map[string]string {
+	for _, key := range previousKeys {
+		if _, found := src[key]; !found {
+			delete(dst, key)
+		}
+	}
+
+	for key, value := range src {
+		if dst == nil {
+			dst = make(map[string]string, len(src))
+		}
+
+		dst[key] = value
+	}
+
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return dst
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,126 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// adoptHPA handles the pre-existing HPA that is not controlled by the ScheduledPodAutoscaler
+// according to the adoption policy of the ScheduledPodAutoscaler.
+// It returns true if the HPA has been adopted and can be reconciled.
+func (r *ScheduledPodAutoscalerReconciler) adoptHPA(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler, hpa *hpav2beta2.HorizontalPodAutoscaler) (bool, error) {
+	switch spa.Spec.AdoptionPolicy {
+	case autoscalingv1.AdoptionPolicyAdopt:
+		if adopted, err := r.takeOwnershipOfHPA(ctx, log, spa, hpa); err != nil || !adopted {
+			return false, err
+		}
+
+		message := fmt.Sprintf("HPA %s has been adopted.", hpa.Name)
+		setHPAAdoption(&spa.Status, autoscalingv1.HPAAdopted, message)
+		r.Recorder.Eventf(spa, corev1.EventTypeNormal, "Adopted", "The HPA %s was adopted.", hpa.Name)
+
+		return true, nil
+	case autoscalingv1.AdoptionPolicyIgnore:
+		status := spa.Status.DeepCopy()
+
+		message := fmt.Sprintf("HPA %s is not controlled by the ScheduledPodAutoscaler and is ignored.", hpa.Name)
+		setHPAAdoption(&spa.Status, autoscalingv1.HPAIgnored, message)
+
+		return false, r.updateScheduledPodAutoscalerStatus(ctx, log, spa, status)
+	default:
+		status := spa.Status.DeepCopy()
+
+		message := fmt.Sprintf("HPA %s already exists and is not controlled by the ScheduledPodAutoscaler. "+
+			"Set the adoption policy to Adopt to take the ownership of it.", hpa.Name)
+		setHPAAdoption(&spa.Status, autoscalingv1.HPARejected, message)
+
+		if setScheduledPodAutoscalerCondition(&spa.Status, spa.Generation,
+			autoscalingv1.ScheduledPodAutoscalerDegraded, autoscalingv1.ReasonHPANotOwned, message) {
+			r.Recorder.Event(spa, corev1.EventTypeWarning, autoscalingv1.ReasonHPANotOwned, message)
+		}
+
+		return false, r.updateScheduledPodAutoscalerStatus(ctx, log, spa, status)
+	}
+}
+
+// takeOwnershipOfHPA records the original spec of the HPA and sets the ScheduledPodAutoscaler
+// as the controller of the HPA. It returns false if the HPA is controlled by another resource.
+func (r *ScheduledPodAutoscalerReconciler) takeOwnershipOfHPA(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler, hpa *hpav2beta2.HorizontalPodAutoscaler) (bool, error) {
+	if _, found := hpa.Annotations[autoscalingv1.AnnotationOriginalHPASpec]; !found {
+		original, err := json.Marshal(hpa.Spec)
+		if err != nil {
+			return false, err
+		}
+
+		metav1.SetMetaDataAnnotation(&hpa.ObjectMeta, autoscalingv1.AnnotationOriginalHPASpec, string(original))
+	}
+
+	if err := ctrl.SetControllerReference(spa, hpa, r.Scheme); err != nil {
+		log.Info("unable to adopt HPA", "hpa", hpa, "reason", err.Error())
+
+		status := spa.Status.DeepCopy()
+
+		message := fmt.Sprintf("Failed to adopt HPA %s: %s", hpa.Name, err)
+		setHPAAdoption(&spa.Status, autoscalingv1.HPARejected, message)
+
+		if setScheduledPodAutoscalerCondition(&spa.Status, spa.Generation,
+			autoscalingv1.ScheduledPodAutoscalerDegraded, autoscalingv1.ReasonHPAAdoptFailed, message) {
+			r.Recorder.Event(spa, corev1.EventTypeWarning, autoscalingv1.ReasonHPAAdoptFailed, message)
+		}
+
+		return false, r.updateScheduledPodAutoscalerStatus(ctx, log, spa, status)
+	}
+
+	if err := r.updateHPAObject(ctx, hpa); err != nil {
+		log.Error(err, "unable to adopt HPA", "hpa", hpa)
+
+		return false, err
+	}
+
+	log.Info("successfully adopt HPA", "hpa", hpa)
+
+	return true, nil
+}
+
+// setHPAAdoption sets the outcome of the adoption to the ScheduledPodAutoscaler status.
+// The time is updated only when the result changes.
+func setHPAAdoption(status *autoscalingv1.ScheduledPodAutoscalerStatus,
+	result autoscalingv1.HPAAdoptionResult, message string) {
+	if status.HPAAdoption != nil && status.HPAAdoption.Result == result {
+		status.HPAAdoption.Message = message
+
+		return
+	}
+
+	status.HPAAdoption = &autoscalingv1.HPAAdoptionStatus{
+		Result:  result,
+		Time:    metav1.Now(),
+		Message: message,
+	}
+}
@@ -0,0 +1,245 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileHPADrift compares the HPA with the spec applied by the controller last time,
+// and applies the drift policy of the ScheduledPodAutoscaler to the desired HPA if the HPA has been changed manually.
+// It records the spec to be applied to the desired HPA.
+func (r *ScheduledPodAutoscalerReconciler) reconcileHPADrift(log logr.Logger, spa *autoscalingv1.ScheduledPodAutoscaler,
+	hpa *hpav2beta2.HorizontalPodAutoscaler, newHPA *hpav2beta2.HorizontalPodAutoscaler) {
+	desired := newHPA.Spec.DeepCopy()
+
+	var live *hpav2beta2.HorizontalPodAutoscalerSpec
+
+	lastApplied, found := lastAppliedHPASpec(hpa)
+	if found {
+		live = withoutDefaultedFields(lastApplied, &hpa.Spec)
+	}
+
+	if !found || equality.Semantic.DeepEqual(lastApplied, live) {
+		setLastAppliedHPASpec(newHPA, desired)
+		setDriftedCondition(&spa.Status, spa.Generation, metav1.ConditionFalse, autoscalingv1.ReasonNoDrift,
+			"The HPA has not been changed manually.")
+
+		return
+	}
+
+	diff := cmp.Diff(lastApplied, live)
+	policy := driftPolicy(spa)
+
+	var reason, message string
+
+	switch {
+	case policy == autoscalingv1.DriftPolicyPause:
+		hpa.Spec.DeepCopyInto(&newHPA.Spec)
+
+		reason = autoscalingv1.ReasonDriftPaused
+		message = "The HPA has been changed manually and the updates are paused until the changes are reverted."
+	case policy == autoscalingv1.DriftPolicyRespectUntilNextTransition &&
+		equality.Semantic.DeepEqual(lastApplied, desired):
+		hpa.Spec.DeepCopyInto(&newHPA.Spec)
+
+		reason = autoscalingv1.ReasonDriftRespected
+		message = "The HPA has been changed manually and the changes are kept until the next transition."
+	default:
+		setLastAppliedHPASpec(newHPA, desired)
+
+		reason = autoscalingv1.ReasonDriftReverted
+		message = "The HPA has been changed manually and the changes are overwritten."
+	}
+
+	// the changed fields are reported instead of the diff, whose format is not stable across the processes,
+	// so that the unchanged drift is not reported again after the controller restarts.
+	message = fmt.Sprintf("%s Changed fields: %s.", message, strings.Join(driftedFields(lastApplied, live), ", "))
+
+	if setDriftedCondition(&spa.Status, spa.Generation, metav1.ConditionTrue, reason, message) {
+		log.Info("detected drift of HPA", "hpa", hpa.Name, "driftPolicy", policy, "diff", diff)
+		r.Recorder.Eventf(spa, corev1.EventTypeWarning, "DriftDetected", "HPA %s: %s", hpa.Name, message)
+		hpaDriftCounter.WithLabelValues(spa.Name, spa.Namespace, string(policy)).Inc()
+	}
+}
+
+// driftedFields returns the sorted JSON paths of the fields of the live spec changed from the last applied spec.
+func driftedFields(lastApplied *hpav2beta2.HorizontalPodAutoscalerSpec,
+	live *hpav2beta2.HorizontalPodAutoscalerSpec) []string {
+	var fields []string
+
+	collectDriftedFields("spec", toJSONValue(lastApplied), toJSONValue(live), &fields)
+	sort.Strings(fields)
+
+	return fields
+}
+
+// toJSONValue returns the generic JSON value of the spec.
+func toJSONValue(spec *hpav2beta2.HorizontalPodAutoscalerSpec) interface{} {
+	var value interface{}
+
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil
+	}
+
+	return value
+}
+
+func collectDriftedFields(path string, lastApplied interface{}, live interface{}, fields *[]string) {
+	lastAppliedMap, lastAppliedIsMap := lastApplied.(map[string]interface{})
+	liveMap, liveIsMap := live.(map[string]interface{})
+
+	if lastAppliedIsMap && liveIsMap {
+		for key, value := range lastAppliedMap {
+			collectDriftedFields(path+"."+key, value, liveMap[key], fields)
+		}
+
+		for key, value := range liveMap {
+			if _, found := lastAppliedMap[key]; !found {
+				collectDriftedFields(path+"."+key, nil, value, fields)
+			}
+		}
+
+		return
+	}
+
+	lastAppliedList, lastAppliedIsList := lastApplied.([]interface{})
+	liveList, liveIsList := live.([]interface{})
+
+	if lastAppliedIsList && liveIsList && len(lastAppliedList) == len(liveList) {
+		for i := range lastAppliedList {
+			collectDriftedFields(fmt.Sprintf("%s[%d]", path, i), lastAppliedList[i], liveList[i], fields)
+		}
+
+		return
+	}
+
+	if !equality.Semantic.DeepEqual(lastApplied, live) {
+		*fields = append(*fields, path)
+	}
+}
+
+// driftPolicy returns the drift policy of the ScheduledPodAutoscaler. It defaults to Revert.
+func driftPolicy(spa *autoscalingv1.ScheduledPodAutoscaler) autoscalingv1.DriftPolicy {
+	if spa.Spec.DriftPolicy == "" {
+		return autoscalingv1.DriftPolicyRevert
+	}
+
+	return spa.Spec.DriftPolicy
+}
+
+// lastAppliedHPASpec returns the spec recorded in the HPA annotation when the controller applied it last time.
+func lastAppliedHPASpec(hpa *hpav2beta2.HorizontalPodAutoscaler) (*hpav2beta2.HorizontalPodAutoscalerSpec, bool) {
+	value, found := hpa.Annotations[autoscalingv1.AnnotationLastAppliedSpec]
+	if !found {
+		return nil, false
+	}
+
+	var spec hpav2beta2.HorizontalPodAutoscalerSpec
+	if err := json.Unmarshal([]byte(value), &spec); err != nil {
+		// the broken annotation is overwritten, so the HPA is regarded as not drifted.
+		return nil, false
+	}
+
+	return &spec, true
+}
+
+// withoutDefaultedFields returns the live spec without the fields defaulted by the API server
+// that are not set in the last applied spec, so that the defaulting is not detected as the drift.
+func withoutDefaultedFields(lastApplied *hpav2beta2.HorizontalPodAutoscalerSpec,
+	live *hpav2beta2.HorizontalPodAutoscalerSpec) *hpav2beta2.HorizontalPodAutoscalerSpec {
+	spec := live.DeepCopy()
+
+	if lastApplied.MinReplicas == nil {
+		spec.MinReplicas = nil
+	}
+
+	if len(lastApplied.Metrics) == 0 {
+		spec.Metrics = nil
+	}
+
+	if lastApplied.Behavior == nil {
+		spec.Behavior = nil
+	} else if spec.Behavior != nil {
+		spec.Behavior.ScaleUp = withoutDefaultedRules(lastApplied.Behavior.ScaleUp, spec.Behavior.ScaleUp)
+		spec.Behavior.ScaleDown = withoutDefaultedRules(lastApplied.Behavior.ScaleDown, spec.Behavior.ScaleDown)
+	}
+
+	return spec
+}
+
+func withoutDefaultedRules(lastApplied *hpav2beta2.HPAScalingRules,
+	live *hpav2beta2.HPAScalingRules) *hpav2beta2.HPAScalingRules {
+	if lastApplied == nil || live == nil {
+		return nil
+	}
+
+	if lastApplied.StabilizationWindowSeconds == nil {
+		live.StabilizationWindowSeconds = nil
+	}
+
+	if lastApplied.SelectPolicy == nil {
+		live.SelectPolicy = nil
+	}
+
+	if len(lastApplied.Policies) == 0 {
+		live.Policies = nil
+	}
+
+	return live
+}
+
+func setLastAppliedHPASpec(hpa *hpav2beta2.HorizontalPodAutoscaler, spec *hpav2beta2.HorizontalPodAutoscalerSpec) {
+	value, _ := json.Marshal(spec)
+	metav1.SetMetaDataAnnotation(&hpa.ObjectMeta, autoscalingv1.AnnotationLastAppliedSpec, string(value))
+}
+
+// setDriftedCondition sets the Drifted condition to the ScheduledPodAutoscaler status.
+// It returns true if the HPA is newly drifted or the drift has changed.
+func setDriftedCondition(status *autoscalingv1.ScheduledPodAutoscalerStatus, generation int64,
+	conditionStatus metav1.ConditionStatus, reason string, message string) bool {
+	current := meta.FindStatusCondition(status.Conditions, autoscalingv1.ConditionDrifted)
+	changed := current == nil || current.Status != conditionStatus || current.Reason != reason ||
+		current.Message != message
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               autoscalingv1.ConditionDrifted,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+
+	return changed && conditionStatus == metav1.ConditionTrue
+}
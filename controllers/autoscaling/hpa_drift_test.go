@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"github.com/d-kuro/scheduled-pod-autoscaler/controllers/autoscaling/internal/testutil"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = ginkgo.Describe("driftedFields", func() {
+	newSpec := func() *hpav2beta2.HorizontalPodAutoscalerSpec {
+		return &hpav2beta2.HorizontalPodAutoscalerSpec{
+			MinReplicas: testutil.ToPointerInt32(1),
+			MaxReplicas: 10,
+			Metrics: []hpav2beta2.MetricSpec{
+				{
+					Type: hpav2beta2.ResourceMetricSourceType,
+					Resource: &hpav2beta2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: hpav2beta2.MetricTarget{
+							Type:               hpav2beta2.UtilizationMetricType,
+							AverageUtilization: testutil.ToPointerInt32(50),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	ginkgo.It("should return the sorted paths of the changed fields", func() {
+		lastApplied := newSpec()
+		live := newSpec()
+		live.MaxReplicas = 20
+		live.MinReplicas = nil
+		live.Metrics[0].Resource.Target.AverageUtilization = testutil.ToPointerInt32(80)
+
+		gomega.Expect(driftedFields(lastApplied, live)).Should(gomega.Equal([]string{
+			"spec.maxReplicas",
+			"spec.metrics[0].resource.target.averageUtilization",
+			"spec.minReplicas",
+		}))
+	})
+
+	ginkgo.It("should report the list whose length is changed as a whole", func() {
+		lastApplied := newSpec()
+		live := newSpec()
+		live.Metrics = append(live.Metrics, hpav2beta2.MetricSpec{
+			Type: hpav2beta2.ResourceMetricSourceType,
+			Resource: &hpav2beta2.ResourceMetricSource{
+				Name: corev1.ResourceMemory,
+				Target: hpav2beta2.MetricTarget{
+					Type:         hpav2beta2.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(1024, resource.BinarySI),
+				},
+			},
+		})
+
+		gomega.Expect(driftedFields(lastApplied, live)).Should(gomega.Equal([]string{"spec.metrics"}))
+	})
+
+	ginkgo.It("should return the same fields regardless of the calls", func() {
+		lastApplied := newSpec()
+		live := newSpec()
+		live.MaxReplicas = 20
+		live.Metrics[0].Resource.Target.AverageUtilization = testutil.ToPointerInt32(80)
+
+		gomega.Expect(driftedFields(lastApplied, live)).Should(gomega.Equal(driftedFields(lastApplied, live)))
+	})
+})
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("DetectHPAGroupVersion", func() {
+	newDiscovery := func(groupVersions ...schema.GroupVersion) *fakediscovery.FakeDiscovery {
+		fake := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		for _, gv := range groupVersions {
+			fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+				GroupVersion: gv.String(),
+				APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler"}},
+			})
+		}
+
+		return fake
+	}
+
+	ginkgo.It("should prefer autoscaling/v2", func() {
+		gv, err := DetectHPAGroupVersion(newDiscovery(HPAGroupVersionV2beta2, HPAGroupVersionV2))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(gv).To(gomega.Equal(HPAGroupVersionV2))
+	})
+
+	ginkgo.It("should fall back to autoscaling/v2beta2", func() {
+		gv, err := DetectHPAGroupVersion(newDiscovery(HPAGroupVersionV2beta2))
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		gomega.Expect(gv).To(gomega.Equal(HPAGroupVersionV2beta2))
+	})
+
+	ginkgo.It("should fail without any supported version", func() {
+		_, err := DetectHPAGroupVersion(newDiscovery())
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
+
+var _ = ginkgo.Describe("autoscaling/v2 HPA", func() {
+	const name = "autoscaling-v2-hpa-test"
+
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: defaultTestNamespace, Name: name}
+
+	newReconciler := func() *ScheduledPodAutoscalerReconciler {
+		scheme := runtime.NewScheme()
+		gomega.Expect(autoscalingv1.AddToScheme(scheme)).To(gomega.Succeed())
+
+		return &ScheduledPodAutoscalerReconciler{
+			Client:          fake.NewClientBuilder().WithScheme(scheme).Build(),
+			Scheme:          scheme,
+			HPAGroupVersion: HPAGroupVersionV2,
+		}
+	}
+
+	ginkgo.It("should watch the HPAs with autoscaling/v2", func() {
+		r := newReconciler()
+
+		obj, ok := r.hpaObject().(*unstructured.Unstructured)
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(obj.GroupVersionKind()).To(gomega.Equal(HPAGroupVersionV2.WithKind("HorizontalPodAutoscaler")))
+	})
+
+	ginkgo.It("should create, get, update and delete the HPA with autoscaling/v2", func() {
+		r := newReconciler()
+		spa := newScheduledPodAutoscaler(name)
+		hpa := newHPA(spa)
+
+		gomega.Expect(r.createHPAObject(ctx, &hpa)).To(gomega.Succeed())
+		gomega.Expect(hpa.ResourceVersion).NotTo(gomega.BeEmpty())
+
+		stored := &unstructured.Unstructured{}
+		stored.SetGroupVersionKind(HPAGroupVersionV2.WithKind("HorizontalPodAutoscaler"))
+		gomega.Expect(r.Get(ctx, key, stored)).To(gomega.Succeed())
+		gomega.Expect(stored.GetAPIVersion()).To(gomega.Equal("autoscaling/v2"))
+
+		var fetched hpav2beta2.HorizontalPodAutoscaler
+		gomega.Expect(r.getHPA(ctx, key, &fetched)).To(gomega.Succeed())
+		gomega.Expect(fetched.TypeMeta).To(gomega.Equal(metav1.TypeMeta{}))
+		gomega.Expect(fetched.Spec).To(gomega.Equal(spa.Spec.HorizontalPodAutoscalerSpec))
+
+		fetched.Spec.MaxReplicas = 10
+		gomega.Expect(r.updateHPAObject(ctx, &fetched)).To(gomega.Succeed())
+
+		var updated hpav2beta2.HorizontalPodAutoscaler
+		gomega.Expect(r.getHPA(ctx, key, &updated)).To(gomega.Succeed())
+		gomega.Expect(updated.Spec.MaxReplicas).To(gomega.Equal(int32(10)))
+
+		gomega.Expect(r.deleteHPAObject(ctx, &updated)).To(gomega.Succeed())
+
+		err := r.getHPA(ctx, key, &updated)
+		gomega.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should enqueue the owner ScheduledPodAutoscaler of the autoscaling/v2 HPA", func() {
+		r := newReconciler()
+		spa := newScheduledPodAutoscaler(name)
+		spa.UID = "spa-uid"
+		hpa := newHPA(spa)
+		gomega.Expect(ctrl.SetControllerReference(spa, &hpa, r.Scheme)).To(gomega.Succeed())
+
+		obj, err := r.toHPAObject(&hpa)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{autoscalingv1.GroupVersion})
+		mapper.Add(autoscalingv1.GroupVersion.WithKind("ScheduledPodAutoscaler"), meta.RESTScopeNamespace)
+
+		// The same handler as Owns(r.hpaObject()) in SetupWithManager.
+		owner := &handler.EnqueueRequestForOwner{OwnerType: &autoscalingv1.ScheduledPodAutoscaler{}, IsController: true}
+		gomega.Expect(owner.InjectScheme(r.Scheme)).To(gomega.Succeed())
+		gomega.Expect(owner.InjectMapper(mapper)).To(gomega.Succeed())
+
+		queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer queue.ShutDown()
+
+		owner.Create(event.CreateEvent{Object: obj}, queue)
+		gomega.Expect(queue.Len()).To(gomega.Equal(1))
+
+		item, _ := queue.Get()
+		gomega.Expect(item).To(gomega.Equal(reconcile.Request{NamespacedName: key}))
+	})
+})
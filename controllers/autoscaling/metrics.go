@@ -23,8 +23,79 @@ var (
 		},
 		[]string{"name", "namespace"},
 	)
+
+	hpaDriftCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "scheduled_pod_auroscaler_hpa_drift_total",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Number of times the HPA changed manually was detected by the scheduled pod autoscaler",
+		},
+		[]string{"name", "namespace", "policy"},
+	)
+
+	transitionQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:      "scheduled_pod_auroscaler_transition_queue_depth",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Number of scheduled pod autoscalers waiting for the next transition in the transition scheduler",
+		},
+	)
+
+	transitionEnqueuedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name:      "scheduled_pod_auroscaler_transition_enqueued_total",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Number of scheduled pod autoscalers enqueued at their transitions by the transition scheduler",
+		},
+	)
+
+	statusWriteFailureCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "scheduled_pod_auroscaler_status_write_failures_total",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Number of failures to write the status of the resources by the scheduled pod autoscaler",
+		},
+		[]string{"kind"},
+	)
+
+	storageVersionMigrationFailureCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "scheduled_pod_auroscaler_storage_version_migration_failures_total",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Number of custom resources failed to be rewritten in the storage version by the scheduled pod autoscaler",
+		},
+		[]string{"kind"},
+	)
+
+	dryRunMinReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "scheduled_pod_auroscaler_dry_run_min_replicas",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Lower limit for the number of pods that would be set by the scheduled pod autoscaler in the dry-run mode",
+		},
+		[]string{"name", "namespace"},
+	)
+
+	dryRunMaxReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "scheduled_pod_auroscaler_dry_run_max_replicas",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Upper limit for the number of pods that would be set by the scheduled pod autoscaler in the dry-run mode",
+		},
+		[]string{"name", "namespace"},
+	)
+
+	shardMembersGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:      "scheduled_pod_auroscaler_shard_members",
+			Namespace: "scheduled_pod_auroscaler_controller",
+			Help:      "Number of live replicas sharing the namespaces seen by the scheduled pod autoscaler",
+		},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(minReplicasCounter, maxReplicasCounter)
+	metrics.Registry.MustRegister(minReplicasCounter, maxReplicasCounter, hpaDriftCounter,
+		transitionQueueDepth, transitionEnqueuedCounter, statusWriteFailureCounter, shardMembersGauge,
+		dryRunMinReplicasGauge, dryRunMaxReplicasGauge, storageVersionMigrationFailureCounter)
 }
@@ -0,0 +1,38 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// excludeNamespaces returns the predicate that filters out the events of the objects in the namespaces.
+// The namespaces watched by the controllers are restricted by the cache of the manager,
+// while the excluded namespaces are filtered out by the predicate since the cache cannot exclude them.
+func excludeNamespaces(namespaces []string) predicate.Predicate {
+	excluded := make(map[string]struct{}, len(namespaces))
+	for _, namespace := range namespaces {
+		excluded[namespace] = struct{}{}
+	}
+
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		_, found := excluded[obj.GetNamespace()]
+
+		return !found
+	})
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = ginkgo.Describe("excludeNamespaces", func() {
+	predicate := excludeNamespaces([]string{"kube-system"})
+
+	ginkgo.It("should filter out the events in the excluded namespaces", func() {
+		spa := newScheduledPodAutoscaler("excluded-namespace-test")
+		spa.Namespace = "kube-system"
+
+		gomega.Expect(predicate.Create(event.CreateEvent{Object: spa})).To(gomega.BeFalse())
+		gomega.Expect(predicate.Generic(event.GenericEvent{Object: spa})).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should pass the events in the other namespaces", func() {
+		spa := newScheduledPodAutoscaler("excluded-namespace-test")
+
+		gomega.Expect(predicate.Create(event.CreateEvent{Object: spa})).To(gomega.BeTrue())
+		gomega.Expect(predicate.Generic(event.GenericEvent{Object: spa})).To(gomega.BeTrue())
+	})
+})
@@ -0,0 +1,118 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultMaxRequeueAfter is the default upper limit of the time until the ScheduledPodAutoscaler is reconciled again
+// without the TransitionScheduler.
+// It is a safety net for the missed events, e.g. the changes of the HPA not controlled by the ScheduledPodAutoscaler.
+const DefaultMaxRequeueAfter = 5 * time.Minute
+
+// forgetTransition removes the ScheduledPodAutoscaler that no longer exists from the TransitionScheduler.
+func (r *ScheduledPodAutoscalerReconciler) forgetTransition(key types.NamespacedName) {
+	if r.Scheduler != nil {
+		r.Scheduler.Forget(key)
+	}
+}
+
+// maxRequeueAfter returns the upper limit of the time until the ScheduledPodAutoscaler is reconciled again.
+func (r *ScheduledPodAutoscalerReconciler) maxRequeueAfter() time.Duration {
+	if r.MaxRequeueAfter <= 0 {
+		return DefaultMaxRequeueAfter
+	}
+
+	return r.MaxRequeueAfter
+}
+
+// requeueAfter returns the time until the next transition of the child Schedules of the ScheduledPodAutoscaler,
+// capped by the upper limit. The changes of the Schedules and the HPA trigger the reconciliation by the watches.
+// With the TransitionScheduler, the next transition is registered to the scheduler,
+// and the ScheduledPodAutoscaler is not requeued unless the upper limit is set explicitly,
+// so that the idle ScheduledPodAutoscalers are not polled. The sync period still resyncs them.
+func (r *ScheduledPodAutoscalerReconciler) requeueAfter(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler, now time.Time) time.Duration {
+	limit := r.maxRequeueAfter()
+
+	var schedules autoscalingv1.ScheduleList
+	if err := r.List(ctx, &schedules, client.MatchingFields(map[string]string{ownerControllerField: spa.Name})); err != nil {
+		log.Error(err, "unable to list child Schedules", "scheduledPodAutoscaler", spa)
+
+		return limit
+	}
+
+	next := nextTransition(log, spa, schedules.Items, now)
+
+	if r.Scheduler != nil {
+		r.Scheduler.Schedule(client.ObjectKeyFromObject(spa), next)
+
+		if r.MaxRequeueAfter <= 0 {
+			return 0
+		}
+
+		return r.MaxRequeueAfter
+	}
+
+	if next.IsZero() || next.Sub(now) > limit {
+		return limit
+	}
+
+	return next.Sub(now)
+}
+
+// nextTransition returns the earliest time at which the HPA of the ScheduledPodAutoscaler may change,
+// that is, the next start or end time of the Schedules and the end of the minimum hold duration.
+// The zero time is returned if there is no such time.
+func nextTransition(log logr.Logger, spa *autoscalingv1.ScheduledPodAutoscaler,
+	schedules []autoscalingv1.Schedule, now time.Time) time.Time {
+	var next time.Time
+
+	earlier := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Spec.Suspend {
+			continue
+		}
+
+		transitions, err := schedule.Spec.Transitions(now)
+		if err != nil {
+			log.Info("unable to calculate schedule transitions", "schedule", schedule.Name, "reason", err.Error())
+
+			continue
+		}
+
+		earlier(transitions.Next())
+	}
+
+	if spa.IsHeld(now) {
+		earlier(spa.Status.LastScaleTime.Add(spa.Spec.MinimumHoldDuration.Duration))
+	}
+
+	return next
+}
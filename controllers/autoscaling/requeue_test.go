@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scheduleLister returns the schedules for any list of the Schedules regardless of the field selectors.
+type scheduleLister struct {
+	client.Client
+	schedules []autoscalingv1.Schedule
+}
+
+func (c *scheduleLister) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	list.(*autoscalingv1.ScheduleList).Items = c.schedules
+
+	return nil
+}
+
+var _ = ginkgo.Describe("nextTransition", func() {
+	now := time.Date(2020, 11, 2, 9, 30, 0, 0, time.UTC)
+
+	newDailySchedule := func(name string, startTime string, endTime string,
+		options ...func(*autoscalingv1.Schedule)) autoscalingv1.Schedule {
+		options = append([]func(*autoscalingv1.Schedule){
+			WithScheduleType(autoscalingv1.Daily),
+			WithScheduleStartTime(startTime),
+			WithScheduleEndTime(endTime),
+			WithScheduleTimeZone("UTC"),
+		}, options...)
+
+		return *newSchedule(name, options...)
+	}
+
+	ginkgo.It("should return the earliest transition of the schedules", func() {
+		spa := newScheduledPodAutoscaler("next-transition-test")
+		schedules := []autoscalingv1.Schedule{
+			newDailySchedule("starts-at-ten", "10:00", "12:00"),
+			newDailySchedule("ends-at-eleven", "09:00", "11:00"),
+		}
+
+		next := nextTransition(logr.Discard(), spa, schedules, now)
+		gomega.Expect(next).To(gomega.Equal(time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)))
+	})
+
+	ginkgo.It("should ignore the suspended schedules", func() {
+		spa := newScheduledPodAutoscaler("next-transition-test")
+		schedules := []autoscalingv1.Schedule{
+			newDailySchedule("starts-at-ten", "10:00", "12:00", WithScheduleSuspend(true)),
+		}
+
+		next := nextTransition(logr.Discard(), spa, schedules, now)
+		gomega.Expect(next.IsZero()).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should return the end of the minimum hold duration", func() {
+		spa := newScheduledPodAutoscaler("next-transition-test",
+			WithScheduledPodAutoscalerMinimumHoldDuration(10*time.Minute))
+		spa.Status.LastScaleTime = &metav1.Time{Time: now.Add(-5 * time.Minute)}
+		schedules := []autoscalingv1.Schedule{
+			newDailySchedule("starts-at-ten", "10:00", "12:00"),
+		}
+
+		next := nextTransition(logr.Discard(), spa, schedules, now)
+		gomega.Expect(next).To(gomega.Equal(now.Add(5 * time.Minute)))
+	})
+})
+
+var _ = ginkgo.Describe("requeueAfter", func() {
+	now := time.Date(2020, 11, 2, 9, 30, 0, 0, time.UTC)
+
+	newReconciler := func(scheduler *TransitionScheduler, maxRequeueAfter time.Duration) *ScheduledPodAutoscalerReconciler {
+		return &ScheduledPodAutoscalerReconciler{
+			Client: &scheduleLister{schedules: []autoscalingv1.Schedule{
+				*newSchedule("starts-at-ten",
+					WithScheduleType(autoscalingv1.Daily),
+					WithScheduleStartTime("10:00"),
+					WithScheduleEndTime("12:00"),
+					WithScheduleTimeZone("UTC")),
+			}},
+			MaxRequeueAfter: maxRequeueAfter,
+			Scheduler:       scheduler,
+		}
+	}
+
+	ginkgo.It("should requeue at the next transition capped by the upper limit without the scheduler", func() {
+		spa := newScheduledPodAutoscaler("requeue-after-test")
+
+		r := newReconciler(nil, 0)
+		gomega.Expect(r.requeueAfter(context.Background(), logr.Discard(), spa, now)).To(gomega.Equal(DefaultMaxRequeueAfter))
+
+		r = newReconciler(nil, time.Hour)
+		gomega.Expect(r.requeueAfter(context.Background(), logr.Discard(), spa, now)).To(gomega.Equal(30 * time.Minute))
+	})
+
+	ginkgo.It("should register the transition and not requeue with the scheduler", func() {
+		spa := newScheduledPodAutoscaler("requeue-after-test")
+		scheduler := NewTransitionScheduler(logr.Discard())
+
+		r := newReconciler(scheduler, 0)
+		gomega.Expect(r.requeueAfter(context.Background(), logr.Discard(), spa, now)).To(gomega.BeZero())
+		gomega.Expect(scheduler.transitions).To(gomega.HaveKey(client.ObjectKeyFromObject(spa)))
+		gomega.Expect(scheduler.transitions[client.ObjectKeyFromObject(spa)].time).
+			To(gomega.Equal(time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)))
+	})
+
+	ginkgo.It("should requeue after the upper limit set explicitly with the scheduler", func() {
+		spa := newScheduledPodAutoscaler("requeue-after-test")
+
+		r := newReconciler(NewTransitionScheduler(logr.Discard()), time.Hour)
+		gomega.Expect(r.requeueAfter(context.Background(), logr.Discard(), spa, now)).To(gomega.Equal(time.Hour))
+	})
+})
@@ -18,17 +18,23 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 // ScheduleReconciler reconciles a Schedule object.
@@ -37,6 +43,18 @@ type ScheduleReconciler struct {
 	Log      logr.Logger
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// FieldManager is the field manager of the server-side apply of the statuses.
+	// The statuses are updated without the server-side apply if it is empty.
+	FieldManager string
+	// APIReader reads the resources without the cache to retry the status writes on conflict.
+	// It defaults to the client.
+	APIReader client.Reader
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles. It defaults to 1.
+	MaxConcurrentReconciles int
+	// ExcludedNamespaces is the list of the namespaces ignored by the reconciler.
+	ExcludedNamespaces []string
+	// Sharder restricts the reconciler to the namespaces owned by the replica if it is set.
+	Sharder *Sharder
 }
 
 // +kubebuilder:rbac:groups=autoscaling.d-kuro.github.io,resources=schedules,verbs=get;list;watch;create;update;patch;delete
@@ -69,6 +87,14 @@ func (r *ScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if err := r.Get(ctx, namespacedName, &spa); err != nil {
 		log.Error(err, "unable to fetch ScheduledPodAutoscaler", "namespacedName", namespacedName)
 
+		if apierrors.IsNotFound(err) {
+			message := fmt.Sprintf("ScheduledPodAutoscaler %s is not found.", namespacedName)
+			if err := r.updateScheduleStatusWithReason(ctx, log, schedule,
+				autoscalingv1.ReasonTargetNotFound, message); err != nil {
+				log.Error(err, "unable to update schedule status", "schedule", schedule)
+			}
+		}
+
 		return ctrl.Result{}, err
 	}
 
@@ -88,6 +114,34 @@ func (r *ScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Info("successfully update Schedule", "schedule", schedule)
 	}
 
+	var result ctrl.Result
+
+	now := time.Now()
+	transitions, err := schedule.Spec.Transitions(now)
+	if err != nil {
+		log.Error(err, "unable to calculate schedule transitions", "schedule", schedule)
+
+		if err := r.updateScheduleStatusWithReason(ctx, log, schedule,
+			autoscalingv1.ConditionReason(err), err.Error()); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	if schedule.Spec.Suspend {
+		transitions.NextStartTime = time.Time{}
+		transitions.NextEndTime = time.Time{}
+	}
+
+	if next := transitions.Next(); !next.IsZero() {
+		result.RequeueAfter = next.Sub(now)
+	}
+
+	if err := r.updateScheduleTransitions(ctx, log, &schedule, transitions); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if schedule.Spec.Suspend {
 		if schedule.Status.Condition != autoscalingv1.ScheduleSuspend {
 			if err := r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleSuspend); err != nil {
@@ -95,50 +149,129 @@ func (r *ScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			}
 		}
 
-		return ctrl.Result{}, nil
+		return result, nil
 	}
 
 	if schedule.Status.Condition != autoscalingv1.ScheduleSuspend &&
-		schedule.Status.Condition != autoscalingv1.ScheduleCompleted {
+		schedule.Status.Condition != autoscalingv1.ScheduleProgressing &&
+		schedule.Status.Condition != autoscalingv1.ScheduleCompleted &&
+		!isDegradedByScheduledPodAutoscaler(schedule.Status) {
 		if err := r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleAvailable); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// scheduleDegradedReasons are the reasons of the Degraded condition set by the Schedule controller.
+// The other reasons are set by the ScheduledPodAutoscaler controller, which clears them.
+var scheduleDegradedReasons = map[string]bool{
+	autoscalingv1.ReasonTargetNotFound:  true,
+	autoscalingv1.ReasonInvalidTimeZone: true,
+	autoscalingv1.ReasonParseError:      true,
+}
+
+// isDegradedByScheduledPodAutoscaler returns true if the schedule has been degraded by
+// the ScheduledPodAutoscaler controller, such as when the HPA could not be updated.
+func isDegradedByScheduledPodAutoscaler(status autoscalingv1.ScheduleStatus) bool {
+	if status.Condition != autoscalingv1.ScheduleDegraded {
+		return false
+	}
+
+	condition := meta.FindStatusCondition(status.Conditions, autoscalingv1.ConditionDegraded)
+
+	return condition != nil && !scheduleDegradedReasons[condition.Reason]
+}
+
+// updateScheduleStatusWithReason updates the schedule status to Degraded with the reason and message.
+func (r *ScheduleReconciler) updateScheduleStatusWithReason(ctx context.Context, log logr.Logger,
+	schedule autoscalingv1.Schedule, reason string, message string) error {
+	updated, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, &schedule, func() bool {
+		return setScheduleCondition(&schedule.Status, schedule.Generation,
+			autoscalingv1.ScheduleDegraded, reason, message)
+	})
+	if err != nil {
+		log.Error(err, "unable to update schedule status", "schedule", schedule)
+
+		return err
+	}
+
+	if updated {
+		r.Recorder.Event(&schedule, corev1.EventTypeWarning, reason, message)
+	}
+
+	return nil
+}
+
+func (r *ScheduleReconciler) updateScheduleTransitions(ctx context.Context, log logr.Logger,
+	schedule *autoscalingv1.Schedule, transitions autoscalingv1.ScheduleTransitions) error {
+	if _, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, schedule, func() bool {
+		return setScheduleTransitions(&schedule.Status, transitions)
+	}); err != nil {
+		log.Error(err, "unable to update schedule status", "schedule", schedule)
+
+		return err
+	}
+
+	return nil
 }
 
 func (r *ScheduleReconciler) updateScheduleStatus(ctx context.Context, log logr.Logger,
 	schedule autoscalingv1.Schedule, newCondition autoscalingv1.ScheduleConditionType) error {
-	if updated := setScheduleCondition(&schedule.Status, newCondition); updated {
-		r.Recorder.Event(&schedule, corev1.EventTypeNormal, "Updated", "The schedule was updated.")
+	updated, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, &schedule, func() bool {
+		return setScheduleCondition(&schedule.Status, schedule.Generation, newCondition, "", "")
+	})
+	if err != nil {
+		log.Error(err, "unable to update schedule status", "schedule", schedule)
 
-		if err := r.Status().Update(ctx, &schedule); err != nil {
-			log.Error(err, "unable to update schedule status", "schedule", schedule)
+		return err
+	}
 
-			return err
-		}
+	if updated {
+		r.Recorder.Event(&schedule, corev1.EventTypeNormal, "Updated", "The schedule was updated.")
 	}
 
 	return nil
 }
 
-func setScheduleCondition(status *autoscalingv1.ScheduleStatus, newCondition autoscalingv1.ScheduleConditionType) bool {
-	updated := false
+func setScheduleTransitions(status *autoscalingv1.ScheduleStatus, transitions autoscalingv1.ScheduleTransitions) bool {
+	newStatus := status.DeepCopy()
+	newStatus.LastStartTime = toMetaTime(transitions.LastStartTime)
+	newStatus.LastEndTime = toMetaTime(transitions.LastEndTime)
+	newStatus.NextStartTime = toMetaTime(transitions.NextStartTime)
+	newStatus.NextEndTime = toMetaTime(transitions.NextEndTime)
 
-	if status.Condition == newCondition {
-		return updated
+	if equality.Semantic.DeepEqual(status, newStatus) {
+		return false
 	}
 
-	status.Condition = newCondition
-	status.LastTransitionTime = metav1.Now()
-	updated = true
+	*status = *newStatus
 
-	return updated
+	return true
+}
+
+func toMetaTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &metav1.Time{Time: t}
 }
 
 func (r *ScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&autoscalingv1.Schedule{}).
-		Complete(r)
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+
+	if len(r.ExcludedNamespaces) > 0 {
+		builder = builder.WithEventFilter(excludeNamespaces(r.ExcludedNamespaces))
+	}
+
+	if r.Sharder != nil {
+		builder = builder.WithEventFilter(r.Sharder.Predicate()).
+			Watches(r.Sharder.Watch(&autoscalingv1.ScheduleList{}), &handler.EnqueueRequestForObject{})
+	}
+
+	return builder.Complete(r)
 }
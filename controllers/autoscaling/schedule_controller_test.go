@@ -10,6 +10,7 @@ import (
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
 	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -54,12 +55,127 @@ var _ = ginkgo.Describe("Schedule controller", func() {
 					return fmt.Errorf("ownerReference not found")
 				}
 
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should set next transition times", func() {
+			const (
+				name = "schedule-controller-transitions-test"
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name)
+
+			// Set a future time and prevent it from being scheduled scaling
+			startTime := now.AddDate(0, 0, 1).Truncate(time.Minute)
+			endTime := now.AddDate(0, 0, 10).Truncate(time.Minute)
+			schedule := newSchedule(name,
+				WithScheduleType(autoscalingv1.OneShot),
+				WithScheduleStartTime(startTime.Format("2006-01-02T15:04")),
+				WithScheduleEndTime(endTime.Format("2006-01-02T15:04")))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSchedule autoscalingv1.Schedule
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSchedule); err != nil {
+					return err
+				}
+
+				if createdSchedule.Status.NextStartTime == nil || !createdSchedule.Status.NextStartTime.Time.Equal(startTime) {
+					return fmt.Errorf("schedule nextStartTime mismatch: want: %s, got: %v",
+						startTime, createdSchedule.Status.NextStartTime)
+				}
+
+				if createdSchedule.Status.NextEndTime == nil || !createdSchedule.Status.NextEndTime.Time.Equal(endTime) {
+					return fmt.Errorf("schedule nextEndTime mismatch: want: %s, got: %v",
+						endTime, createdSchedule.Status.NextEndTime)
+				}
+
+				if createdSchedule.Status.LastStartTime != nil || createdSchedule.Status.LastEndTime != nil {
+					return fmt.Errorf("schedule lastStartTime and lastEndTime must not be set")
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should set degraded conditions with invalid time zone", func() {
+			const (
+				name = "schedule-controller-invalid-time-zone-test"
+			)
+
+			ctx := context.Background()
+			spa := newScheduledPodAutoscaler(name)
+			schedule := newSchedule(name, WithScheduleTimeZone("Invalid/TimeZone"))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSchedule autoscalingv1.Schedule
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSchedule); err != nil {
+					return err
+				}
+
+				if createdSchedule.Status.Condition != autoscalingv1.ScheduleDegraded {
+					return fmt.Errorf("schedule condition mismatch: want: %s, got: %s",
+						autoscalingv1.ScheduleDegraded, createdSchedule.Status.Condition)
+				}
+
+				ready := meta.FindStatusCondition(createdSchedule.Status.Conditions, autoscalingv1.ConditionReady)
+				if ready == nil || ready.Status != metav1.ConditionFalse {
+					return fmt.Errorf("schedule Ready condition must be False: got: %v", ready)
+				}
+
+				degraded := meta.FindStatusCondition(createdSchedule.Status.Conditions, autoscalingv1.ConditionDegraded)
+				if degraded == nil || degraded.Status != metav1.ConditionTrue ||
+					degraded.Reason != autoscalingv1.ReasonInvalidTimeZone {
+					return fmt.Errorf("schedule Degraded condition mismatch: want reason: %s, got: %v",
+						autoscalingv1.ReasonInvalidTimeZone, degraded)
+				}
+
 				return nil
 			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
 		})
 	})
 })
 
+var _ = ginkgo.Describe("isDegradedByScheduledPodAutoscaler", func() {
+	degradedStatus := func(reason string) autoscalingv1.ScheduleStatus {
+		status := autoscalingv1.ScheduleStatus{}
+		setScheduleCondition(&status, 1, autoscalingv1.ScheduleDegraded, reason, "degraded")
+
+		return status
+	}
+
+	ginkgo.It("should keep the Degraded condition set by the ScheduledPodAutoscaler controller", func() {
+		status := degradedStatus(autoscalingv1.ReasonHPAUpdateFailed)
+		gomega.Expect(isDegradedByScheduledPodAutoscaler(status)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should clear the Degraded condition set by the Schedule controller", func() {
+		status := degradedStatus(autoscalingv1.ReasonTargetNotFound)
+		gomega.Expect(isDegradedByScheduledPodAutoscaler(status)).To(gomega.BeFalse())
+
+		status = degradedStatus(autoscalingv1.ReasonInvalidTimeZone)
+		gomega.Expect(isDegradedByScheduledPodAutoscaler(status)).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should ignore the schedules that are not degraded", func() {
+		status := autoscalingv1.ScheduleStatus{}
+		setScheduleCondition(&status, 1, autoscalingv1.ScheduleAvailable, "", "")
+		gomega.Expect(isDegradedByScheduledPodAutoscaler(status)).To(gomega.BeFalse())
+	})
+})
+
 const (
 	defaultScheduleMinReplicas = 3
 	defaultScheduleMaxReplicas = 10
@@ -134,3 +250,15 @@ func WithScheduleSuspend(value bool) func(*autoscalingv1.Schedule) {
 		schedule.Spec.Suspend = value
 	}
 }
+
+func WithScheduleMode(value autoscalingv1.ScheduleMode) func(*autoscalingv1.Schedule) {
+	return func(schedule *autoscalingv1.Schedule) {
+		schedule.Spec.Mode = value
+	}
+}
+
+func WithScheduleTimeZone(value string) func(*autoscalingv1.Schedule) {
+	return func(schedule *autoscalingv1.Schedule) {
+		schedule.Spec.TimeZone = value
+	}
+}
@@ -18,6 +18,8 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
@@ -28,9 +30,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 // ScheduledPodAutoscalerReconciler reconciles a ScheduledPodAutoscaler object.
@@ -39,10 +45,35 @@ type ScheduledPodAutoscalerReconciler struct {
 	Log      logr.Logger
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// HPAGroupVersion is the API version of the HPAs created and updated by the reconciler.
+	// It defaults to autoscaling/v2beta2. See DetectHPAGroupVersion.
+	HPAGroupVersion schema.GroupVersion
+	// MaxRequeueAfter is the upper limit of the time until the ScheduledPodAutoscaler is reconciled again.
+	// It defaults to DefaultMaxRequeueAfter without the Scheduler, and to no periodic requeue with the Scheduler.
+	MaxRequeueAfter time.Duration
+	// Scheduler enqueues the ScheduledPodAutoscalers at the transitions of their Schedules if it is set.
+	// Otherwise, each ScheduledPodAutoscaler is requeued at the next transition by itself.
+	Scheduler *TransitionScheduler
+	// FieldManager is the field manager of the server-side apply of the HPAs and the statuses.
+	// The HPAs and the statuses are updated without the server-side apply if it is empty.
+	FieldManager string
+	// APIReader reads the resources without the cache to retry the status writes on conflict.
+	// It defaults to the client.
+	APIReader client.Reader
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles. It defaults to 1.
+	MaxConcurrentReconciles int
+	// ExcludedNamespaces is the list of the namespaces ignored by the reconciler.
+	ExcludedNamespaces []string
+	// Sharder restricts the reconciler to the namespaces owned by the replica if it is set.
+	Sharder *Sharder
+	// DryRun puts all the ScheduledPodAutoscalers in the dry-run mode,
+	// in which the min/max replicas are recorded in the status but the HPAs are never written.
+	DryRun bool
 }
 
 // +kubebuilder:rbac:groups=autoscaling.d-kuro.github.io,resources=scheduledpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=autoscaling.d-kuro.github.io,resources=scheduledpodautoscalers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=autoscaling.d-kuro.github.io,resources=scheduledpodautoscalers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 
 func (r *ScheduledPodAutoscalerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -51,6 +82,8 @@ func (r *ScheduledPodAutoscalerReconciler) Reconcile(ctx context.Context, req ct
 	var spa autoscalingv1.ScheduledPodAutoscaler
 	if err := r.Get(ctx, req.NamespacedName, &spa); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.forgetTransition(req.NamespacedName)
+
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 
@@ -60,22 +93,33 @@ func (r *ScheduledPodAutoscalerReconciler) Reconcile(ctx context.Context, req ct
 	}
 
 	if spa.DeletionTimestamp != nil {
-		return ctrl.Result{}, nil
+		r.forgetTransition(req.NamespacedName)
+
+		return ctrl.Result{}, r.finalize(ctx, log, &spa)
+	}
+
+	if err := r.reconcileFinalizer(ctx, log, &spa); err != nil {
+		return ctrl.Result{}, err
 	}
 
+	status := spa.Status.DeepCopy()
+	dryRun := r.isDryRun(&spa)
+
+	hpaKey := types.NamespacedName{Namespace: spa.Namespace, Name: spa.HPAName()}
+
 	var hpa hpav2beta2.HorizontalPodAutoscaler
-	if err := r.Get(ctx, req.NamespacedName, &hpa); apierrors.IsNotFound(err) {
-		log.Info("unable to fetch hpa, try to create one", "namespacedName", req.NamespacedName)
+	if err := r.getHPA(ctx, hpaKey, &hpa); apierrors.IsNotFound(err) && dryRun {
+		log.Info("unable to fetch hpa, calculate replicas without creating one in dry-run mode", "namespacedName", hpaKey)
+
+		hpa = newHPA(&spa)
+	} else if apierrors.IsNotFound(err) {
+		log.Info("unable to fetch hpa, try to create one", "namespacedName", hpaKey)
 
-		hpa, err = r.createHPA(ctx, log, spa)
+		hpa, err = r.createHPA(ctx, log, &spa)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
-		if err := r.updateScheduledPodAutoscalerStatus(ctx, log, spa, autoscalingv1.ScheduledPodAutoscalerAvailable); err != nil {
-			log.Error(err, "unable to update ScheduledPodAutoscaler status", "scheduledPodAutoscaler", spa)
-		}
-
 		log.Info("successfully create HPA", "hpa", hpa)
 
 		if hpa.Spec.MinReplicas != nil {
@@ -84,44 +128,48 @@ func (r *ScheduledPodAutoscalerReconciler) Reconcile(ctx context.Context, req ct
 
 		maxReplicasCounter.WithLabelValues(spa.Name, spa.Namespace).Set(float64(hpa.Spec.MaxReplicas))
 	} else if err != nil {
-		log.Error(err, "unable to fetch HPA", "namespacedName", req.NamespacedName)
+		log.Error(err, "unable to fetch HPA", "namespacedName", hpaKey)
 
 		return ctrl.Result{}, err
+	} else if !dryRun && !metav1.IsControlledBy(&hpa, &spa) {
+		adopted, err := r.adoptHPA(ctx, log, &spa, &hpa)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if !adopted {
+			return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+		}
 	}
 
-	updated, err := r.reconcileHPA(ctx, log, spa, hpa)
-	if err != nil {
+	if _, err := r.reconcileHPA(ctx, log, &spa, hpa); err != nil {
 		log.Error(err, "unable to reconcile")
 
 		return ctrl.Result{}, err
 	}
 
-	if !updated && !equality.Semantic.DeepEqual(hpa.Spec, spa.Spec.HorizontalPodAutoscalerSpec) {
-		hpa.Spec = spa.Spec.HorizontalPodAutoscalerSpec
-		if err := r.Update(ctx, &hpa, &client.UpdateOptions{}); err != nil {
-			log.Error(err, "unable to update HPA", "hpa", hpa)
-
+	if !dryRun {
+		if err := r.deleteRenamedHPA(ctx, log, &spa); err != nil {
 			return ctrl.Result{}, err
 		}
 
-		if err := r.updateScheduledPodAutoscalerStatus(ctx, log, spa, autoscalingv1.ScheduledPodAutoscalerAvailable); err != nil {
-			log.Error(err, "unable to update ScheduledPodAutoscaler status", "scheduledPodAutoscaler", spa)
-		}
-
-		log.Info("successfully update HPA", "hpa", hpa)
+		spa.Status.HPAName = hpaKey.Name
+	}
 
-		if hpa.Spec.MinReplicas != nil {
-			minReplicasCounter.WithLabelValues(spa.Name, spa.Namespace).Set(float64(*hpa.Spec.MinReplicas))
-		}
+	if updated := setScheduledPodAutoscalerCondition(&spa.Status, spa.Generation,
+		autoscalingv1.ScheduledPodAutoscalerAvailable, "", ""); updated {
+		r.Recorder.Event(&spa, corev1.EventTypeNormal, "Updated", "The schedule was updated.")
+	}
 
-		maxReplicasCounter.WithLabelValues(spa.Name, spa.Namespace).Set(float64(hpa.Spec.MaxReplicas))
+	if err := r.updateScheduledPodAutoscalerStatus(ctx, log, &spa, status); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+	return ctrl.Result{RequeueAfter: r.requeueAfter(ctx, log, &spa, time.Now())}, nil
 }
 
 func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log logr.Logger,
-	spa autoscalingv1.ScheduledPodAutoscaler, hpa hpav2beta2.HorizontalPodAutoscaler) (bool, error) {
+	spa *autoscalingv1.ScheduledPodAutoscaler, hpa hpav2beta2.HorizontalPodAutoscaler) (bool, error) {
 	now := time.Now()
 	updated := false
 	var err error
@@ -135,8 +183,6 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 
 	if len(schedules.Items) == 0 {
 		log.Info("not found child Schedules", "scheduledPodAutoscaler", spa)
-
-		return updated, nil
 	}
 
 	var processSchedule []autoscalingv1.Schedule
@@ -145,6 +191,10 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 		schedule := schedule
 
 		if schedule.Spec.Suspend {
+			if err := r.updateScheduleSnapshot(ctx, log, &schedule, nil); err != nil {
+				log.Error(err, "unable to clear schedule snapshot", "schedule", schedule)
+			}
+
 			continue
 		}
 
@@ -152,10 +202,19 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 		if err != nil {
 			log.Error(err, "unable to check completed Schedule")
 
-			return updated, err
+			if err := r.updateScheduleStatusWithReason(ctx, log, schedule,
+				autoscalingv1.ConditionReason(err), err.Error()); err != nil {
+				log.Error(err, "unable to update schedule status", "schedule", schedule)
+			}
+
+			continue
 		}
 
 		if completed {
+			if err := r.updateScheduleSnapshot(ctx, log, &schedule, nil); err != nil {
+				log.Error(err, "unable to clear schedule snapshot", "schedule", schedule)
+			}
+
 			if err = r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleCompleted); err != nil {
 				log.Error(err, "unable to update schedule status", "schedule", schedule)
 			}
@@ -167,7 +226,12 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 		if err != nil {
 			log.Error(err, "unable to check contains Schedule")
 
-			return updated, err
+			if err := r.updateScheduleStatusWithReason(ctx, log, schedule,
+				autoscalingv1.ConditionReason(err), err.Error()); err != nil {
+				log.Error(err, "unable to update schedule status", "schedule", schedule)
+			}
+
+			continue
 		}
 
 		log.Info("checking included in the schedule scaling period",
@@ -181,18 +245,30 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 		)
 
 		if isContains {
+			if schedule.Spec.Mode == autoscalingv1.Freeze && schedule.Status.SnapshotReplicas == nil {
+				if err := r.snapshotScheduleReplicas(ctx, log, &schedule, hpa); err != nil {
+					return updated, err
+				}
+			}
+
 			processSchedule = append(processSchedule, schedule)
 
 			continue
 		}
 
+		if err := r.updateScheduleSnapshot(ctx, log, &schedule, nil); err != nil {
+			log.Error(err, "unable to clear schedule snapshot", "schedule", schedule)
+		}
+
 		if err = r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleAvailable); err != nil {
 			log.Error(err, "unable to update schedule status", "schedule", schedule)
 		}
 	}
 
-	newMin, newMax := calculateHPAReplica(processSchedule)
+	newMin, newMax, effectiveSchedule := calculateHPAReplica(processSchedule)
 	newHPA := hpa.DeepCopy()
+	spa.Spec.HorizontalPodAutoscalerSpec.DeepCopyInto(&newHPA.Spec)
+	propagateHPAMetadata(spa, newHPA)
 
 	if newMin != nil {
 		newHPA.Spec.MinReplicas = newMin
@@ -202,21 +278,72 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 		newHPA.Spec.MaxReplicas = *newMax
 	}
 
+	// The replicas pinned by a Freeze schedule are not held.
+	if spa.IsHeld(now) && len(freezeSchedules(processSchedule)) == 0 {
+		holdHPAReplica(hpa.Spec, &newHPA.Spec)
+	}
+
+	spa.Status.ActiveSchedules = nil
+	for _, schedule := range processSchedule {
+		spa.Status.ActiveSchedules = append(spa.Status.ActiveSchedules, schedule.Name)
+	}
+
+	sort.Strings(spa.Status.ActiveSchedules)
+	spa.Status.EffectiveSchedule = effectiveSchedule
+
+	if r.isDryRun(spa) {
+		r.recordDryRun(log, spa, hpa, newHPA.Spec, now)
+
+		for _, schedule := range processSchedule {
+			if err := r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleProgressing); err != nil {
+				log.Error(err, "unable to update schedule status", "schedule", schedule)
+			}
+		}
+
+		return updated, nil
+	}
+
+	clearDryRun(spa)
+	r.reconcileHPADrift(log, spa, &hpa, newHPA)
+
 	if equality.Semantic.DeepEqual(hpa, newHPA) {
+		setScheduledPodAutoscalerReplicas(&spa.Status, hpa.Spec)
+
+		for _, schedule := range processSchedule {
+			if err := r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleProgressing); err != nil {
+				log.Error(err, "unable to update schedule status", "schedule", schedule)
+			}
+		}
+
 		return updated, nil
 	}
 
-	updated, err = r.updateHPA(ctx, log, *newHPA)
+	updated, err = r.updateHPA(ctx, log, spa, *newHPA)
 	if err != nil {
+		message := fmt.Sprintf("Failed to update HPA %s: %s", newHPA.Name, err)
+
 		for _, schedule := range processSchedule {
-			if err = r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleDegraded); err != nil {
+			if err := r.updateScheduleStatusWithReason(ctx, log, schedule,
+				autoscalingv1.ReasonHPAUpdateFailed, message); err != nil {
 				log.Error(err, "unable to update schedule status", "schedule", schedule)
 			}
 		}
 
+		if err := r.updateScheduledPodAutoscalerStatusWithReason(ctx, log, spa,
+			autoscalingv1.ReasonHPAUpdateFailed, message); err != nil {
+			log.Error(err, "unable to update ScheduledPodAutoscaler status", "scheduledPodAutoscaler", spa)
+		}
+
 		return updated, err
 	}
 
+	if !equality.Semantic.DeepEqual(hpa.Spec.MinReplicas, newHPA.Spec.MinReplicas) ||
+		hpa.Spec.MaxReplicas != newHPA.Spec.MaxReplicas {
+		spa.Status.LastScaleTime = &metav1.Time{Time: now}
+	}
+
+	setScheduledPodAutoscalerReplicas(&spa.Status, newHPA.Spec)
+
 	for _, schedule := range processSchedule {
 		if err := r.updateScheduleStatus(ctx, log, schedule, autoscalingv1.ScheduleProgressing); err != nil {
 			log.Error(err, "unable to update schedule status", "schedule", schedule)
@@ -227,25 +354,21 @@ func (r *ScheduledPodAutoscalerReconciler) reconcileHPA(ctx context.Context, log
 }
 
 func (r *ScheduledPodAutoscalerReconciler) createHPA(ctx context.Context, log logr.Logger,
-	spa autoscalingv1.ScheduledPodAutoscaler) (hpav2beta2.HorizontalPodAutoscaler, error) {
-	hpa := hpav2beta2.HorizontalPodAutoscaler{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      spa.Name,
-			Namespace: spa.Namespace,
-		},
-		Spec: spa.Spec.HorizontalPodAutoscalerSpec,
-	}
+	spa *autoscalingv1.ScheduledPodAutoscaler) (hpav2beta2.HorizontalPodAutoscaler, error) {
+	hpa := newHPA(spa)
 
-	if err := ctrl.SetControllerReference(&spa, &hpa, r.Scheme); err != nil {
+	if err := ctrl.SetControllerReference(spa, &hpa, r.Scheme); err != nil {
 		log.Error(err, "unable to set ownerReference", "hpa", hpa)
 
 		return hpav2beta2.HorizontalPodAutoscaler{}, err
 	}
 
-	if err := r.Create(ctx, &hpa, &client.CreateOptions{}); err != nil {
+	if err := r.applyHPAObject(ctx, spa, &hpa); err != nil {
 		log.Info("unable to create HPA", "hpa", hpa)
 
-		if err := r.updateScheduledPodAutoscalerStatus(ctx, log, spa, autoscalingv1.ScheduledPodAutoscalerDegraded); err != nil {
+		message := fmt.Sprintf("Failed to create HPA %s: %s", hpa.Name, err)
+		if err := r.updateScheduledPodAutoscalerStatusWithReason(ctx, log, spa,
+			autoscalingv1.ReasonHPACreateFailed, message); err != nil {
 			log.Error(err, "unable to update ScheduledPodAutoscaler status", "scheduledPodAutoscaler", spa)
 		}
 
@@ -255,11 +378,61 @@ func (r *ScheduledPodAutoscalerReconciler) createHPA(ctx context.Context, log lo
 	return hpa, nil
 }
 
+// newHPA returns the HPA to be created for the ScheduledPodAutoscaler.
+func newHPA(spa *autoscalingv1.ScheduledPodAutoscaler) hpav2beta2.HorizontalPodAutoscaler {
+	hpa := hpav2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      spa.HPAName(),
+			Namespace: spa.Namespace,
+		},
+		Spec: spa.Spec.HorizontalPodAutoscalerSpec,
+	}
+
+	propagateHPAMetadata(spa, &hpa)
+	setLastAppliedHPASpec(&hpa, &hpa.Spec)
+
+	return hpa
+}
+
+// deleteRenamedHPA deletes the HPA with the previous name after the HPA name of the ScheduledPodAutoscaler is changed.
+func (r *ScheduledPodAutoscalerReconciler) deleteRenamedHPA(ctx context.Context, log logr.Logger,
+	spa *autoscalingv1.ScheduledPodAutoscaler) error {
+	if spa.Status.HPAName == "" || spa.Status.HPAName == spa.HPAName() {
+		return nil
+	}
+
+	var hpa hpav2beta2.HorizontalPodAutoscaler
+	if err := r.getHPA(ctx, types.NamespacedName{Namespace: spa.Namespace, Name: spa.Status.HPAName}, &hpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		log.Error(err, "unable to fetch HPA with previous name", "name", spa.Status.HPAName)
+
+		return err
+	}
+
+	if !metav1.IsControlledBy(&hpa, spa) {
+		return nil
+	}
+
+	if err := r.deleteHPAObject(ctx, &hpa); client.IgnoreNotFound(err) != nil {
+		log.Error(err, "unable to delete HPA with previous name", "hpa", hpa)
+
+		return err
+	}
+
+	log.Info("successfully delete HPA with previous name", "hpa", hpa)
+	r.Recorder.Eventf(spa, corev1.EventTypeNormal, "Deleted", "The HPA %s was deleted since the HPA name was changed.", hpa.Name)
+
+	return nil
+}
+
 func (r *ScheduledPodAutoscalerReconciler) updateHPA(ctx context.Context, log logr.Logger,
-	hpa hpav2beta2.HorizontalPodAutoscaler) (bool, error) {
+	spa *autoscalingv1.ScheduledPodAutoscaler, hpa hpav2beta2.HorizontalPodAutoscaler) (bool, error) {
 	updated := false
 
-	if err := r.Update(ctx, &hpa, &client.UpdateOptions{}); err != nil {
+	if err := r.applyHPAObject(ctx, spa, &hpa); err != nil {
 		log.Error(err, "unable to update HPA", "hpa", hpa)
 
 		return updated, err
@@ -269,40 +442,109 @@ func (r *ScheduledPodAutoscalerReconciler) updateHPA(ctx context.Context, log lo
 	log.Info("successfully update HPA", "hpa", hpa)
 
 	if hpa.Spec.MinReplicas != nil {
-		minReplicasCounter.WithLabelValues(hpa.Name, hpa.Namespace).Set(float64(*hpa.Spec.MinReplicas))
+		minReplicasCounter.WithLabelValues(spa.Name, spa.Namespace).Set(float64(*hpa.Spec.MinReplicas))
 	}
 
-	maxReplicasCounter.WithLabelValues(hpa.Name, hpa.Namespace).Set(float64(hpa.Spec.MaxReplicas))
+	maxReplicasCounter.WithLabelValues(spa.Name, spa.Namespace).Set(float64(hpa.Spec.MaxReplicas))
 
 	return updated, nil
 }
 
 func (r *ScheduledPodAutoscalerReconciler) updateScheduleStatus(ctx context.Context, log logr.Logger,
 	schedule autoscalingv1.Schedule, newCondition autoscalingv1.ScheduleConditionType) error {
-	if updated := setScheduleCondition(&schedule.Status, newCondition); updated {
+	updated, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, &schedule, func() bool {
+		return setScheduleCondition(&schedule.Status, schedule.Generation, newCondition, "", "")
+	})
+	if err != nil {
+		log.Error(err, "unable to update schedule status", "schedule", schedule)
+
+		return err
+	}
+
+	if updated {
 		r.Recorder.Event(&schedule, corev1.EventTypeNormal, "Updated", "The schedule was updated.")
+	}
 
-		if err := r.Status().Update(ctx, &schedule); err != nil {
-			log.Error(err, "unable to update schedule status", "schedule", schedule)
+	return nil
+}
 
-			return err
-		}
+// updateScheduleStatusWithReason updates the schedule status to Degraded with the reason and message.
+func (r *ScheduledPodAutoscalerReconciler) updateScheduleStatusWithReason(ctx context.Context, log logr.Logger,
+	schedule autoscalingv1.Schedule, reason string, message string) error {
+	updated, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, &schedule, func() bool {
+		return setScheduleCondition(&schedule.Status, schedule.Generation,
+			autoscalingv1.ScheduleDegraded, reason, message)
+	})
+	if err != nil {
+		log.Error(err, "unable to update schedule status", "schedule", schedule)
+
+		return err
+	}
+
+	if updated {
+		r.Recorder.Event(&schedule, corev1.EventTypeWarning, reason, message)
 	}
 
 	return nil
 }
 
-func (r *ScheduledPodAutoscalerReconciler) updateScheduledPodAutoscalerStatus(ctx context.Context, log logr.Logger,
-	spa autoscalingv1.ScheduledPodAutoscaler, newCondition autoscalingv1.ScheduledPodAutoscalerConditionType) error {
-	if updated := setScheduledPodAutoscalerCondition(&spa.Status, newCondition); updated {
-		r.Recorder.Event(&spa, corev1.EventTypeNormal, "Updated", "The schedule was updated.")
+// snapshotScheduleReplicas records the current replicas of the scale target observed by the HPA
+// in the schedule status, so that the frozen replicas survive controller restarts.
+func (r *ScheduledPodAutoscalerReconciler) snapshotScheduleReplicas(ctx context.Context, log logr.Logger,
+	schedule *autoscalingv1.Schedule, hpa hpav2beta2.HorizontalPodAutoscaler) error {
+	if hpa.Status.CurrentReplicas < 1 {
+		log.Info("current replicas of scale target is not observed yet, skip snapshot",
+			"schedule", schedule, "hpa", hpa)
 
-		if err := r.Status().Update(ctx, &spa); err != nil {
-			log.Error(err, "unable to update ScheduledPodAutoscaler status",
-				"scheduledPodAutoscaler", spa)
+		return nil
+	}
 
-			return err
+	replicas := hpa.Status.CurrentReplicas
+	if err := r.updateScheduleSnapshot(ctx, log, schedule, &replicas); err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(schedule, corev1.EventTypeNormal, "Snapshot",
+		"The current replicas %d of the scale target were taken.", replicas)
+
+	return nil
+}
+
+func (r *ScheduledPodAutoscalerReconciler) updateScheduleSnapshot(ctx context.Context, log logr.Logger,
+	schedule *autoscalingv1.Schedule, replicas *int32) error {
+	if _, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, schedule, func() bool {
+		if equality.Semantic.DeepEqual(schedule.Status.SnapshotReplicas, replicas) {
+			return false
 		}
+
+		schedule.Status.SnapshotReplicas = replicas
+
+		return true
+	}); err != nil {
+		log.Error(err, "unable to update schedule status", "schedule", schedule)
+
+		return err
+	}
+
+	return nil
+}
+
+// updateScheduledPodAutoscalerStatusWithReason updates the ScheduledPodAutoscaler status to Degraded
+// with the reason and message.
+func (r *ScheduledPodAutoscalerReconciler) updateScheduledPodAutoscalerStatusWithReason(ctx context.Context,
+	log logr.Logger, spa *autoscalingv1.ScheduledPodAutoscaler, reason string, message string) error {
+	updated, err := updateStatus(ctx, r.Client, r.APIReader, r.FieldManager, spa, func() bool {
+		return setScheduledPodAutoscalerCondition(&spa.Status, spa.Generation,
+			autoscalingv1.ScheduledPodAutoscalerDegraded, reason, message)
+	})
+	if err != nil {
+		log.Error(err, "unable to update ScheduledPodAutoscaler status", "scheduledPodAutoscaler", spa)
+
+		return err
+	}
+
+	if updated {
+		r.Recorder.Event(spa, corev1.EventTypeWarning, reason, message)
 	}
 
 	return nil
@@ -310,15 +552,32 @@ func (r *ScheduledPodAutoscalerReconciler) updateScheduledPodAutoscalerStatus(ct
 
 // calculateHPAReplica calculates minReplicas and maxReplicas of the HPA from one or more schedules.
 // If there is more than one schedule, the maximum value is used for the replicas.
-func calculateHPAReplica(schedules []autoscalingv1.Schedule) (minReplicas *int32, maxReplicas *int32) {
+// A schedule in Freeze mode uses the snapshot replicas for both minReplicas and maxReplicas,
+// and takes precedence over the schedules in Scale mode, which are ignored while it is active.
+// The effective schedule is the schedule that provides minReplicas,
+// or the schedule that provides maxReplicas if no schedule provides minReplicas.
+func calculateHPAReplica(schedules []autoscalingv1.Schedule) (
+	minReplicas *int32, maxReplicas *int32, effectiveSchedule string) {
+	if frozen := freezeSchedules(schedules); len(frozen) > 0 {
+		schedules = frozen
+	}
+
 	var max, min int32
+	var minSchedule, maxSchedule string
 	for _, schedule := range schedules {
-		if schedule.Spec.MinReplicas != nil && *schedule.Spec.MinReplicas > min {
-			min = *schedule.Spec.MinReplicas
+		scheduleMin, scheduleMax := schedule.Spec.MinReplicas, schedule.Spec.MaxReplicas
+		if schedule.Spec.Mode == autoscalingv1.Freeze {
+			scheduleMin, scheduleMax = schedule.Status.SnapshotReplicas, schedule.Status.SnapshotReplicas
 		}
 
-		if schedule.Spec.MaxReplicas != nil && *schedule.Spec.MaxReplicas > max {
-			max = *schedule.Spec.MaxReplicas
+		if scheduleMin != nil && *scheduleMin > min {
+			min = *scheduleMin
+			minSchedule = schedule.Name
+		}
+
+		if scheduleMax != nil && *scheduleMax > max {
+			max = *scheduleMax
+			maxSchedule = schedule.Name
 		}
 	}
 
@@ -330,23 +589,48 @@ func calculateHPAReplica(schedules []autoscalingv1.Schedule) (minReplicas *int32
 		maxReplicas = &max
 	}
 
-	return minReplicas, maxReplicas
+	effectiveSchedule = minSchedule
+	if effectiveSchedule == "" {
+		effectiveSchedule = maxSchedule
+	}
+
+	return minReplicas, maxReplicas, effectiveSchedule
 }
 
-func setScheduledPodAutoscalerCondition(
-	status *autoscalingv1.ScheduledPodAutoscalerStatus,
-	newCondition autoscalingv1.ScheduledPodAutoscalerConditionType) bool {
-	updated := false
+// freezeSchedules returns the schedules in Freeze mode.
+func freezeSchedules(schedules []autoscalingv1.Schedule) []autoscalingv1.Schedule {
+	var frozen []autoscalingv1.Schedule
+	for _, schedule := range schedules {
+		if schedule.Spec.Mode == autoscalingv1.Freeze {
+			frozen = append(frozen, schedule)
+		}
+	}
 
-	if status.Condition == newCondition {
-		return updated
+	return frozen
+}
+
+func setScheduledPodAutoscalerReplicas(
+	status *autoscalingv1.ScheduledPodAutoscalerStatus, spec hpav2beta2.HorizontalPodAutoscalerSpec) {
+	status.CurrentMinReplicas = nil
+	if spec.MinReplicas != nil {
+		min := *spec.MinReplicas
+		status.CurrentMinReplicas = &min
 	}
 
-	status.Condition = newCondition
-	status.LastTransitionTime = metav1.Now()
-	updated = true
+	status.CurrentMaxReplicas = spec.MaxReplicas
+}
+
+// holdHPAReplica prevents the min/max replicas of the HPA from being lowered below the current values.
+// It is used while the minimum hold duration of the ScheduledPodAutoscaler has not elapsed.
+func holdHPAReplica(current hpav2beta2.HorizontalPodAutoscalerSpec, desired *hpav2beta2.HorizontalPodAutoscalerSpec) {
+	if current.MinReplicas != nil && (desired.MinReplicas == nil || *desired.MinReplicas < *current.MinReplicas) {
+		min := *current.MinReplicas
+		desired.MinReplicas = &min
+	}
 
-	return updated
+	if desired.MaxReplicas < current.MaxReplicas {
+		desired.MaxReplicas = current.MaxReplicas
+	}
 }
 
 const ownerControllerField = ".metadata.controller"
@@ -378,9 +662,28 @@ func (r *ScheduledPodAutoscalerReconciler) SetupWithManager(mgr ctrl.Manager) er
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&autoscalingv1.ScheduledPodAutoscaler{}).
 		Owns(&autoscalingv1.Schedule{}).
-		Owns(&hpav2beta2.HorizontalPodAutoscaler{}).
-		Complete(r)
+		Owns(r.hpaObject()).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+
+	if len(r.ExcludedNamespaces) > 0 {
+		builder = builder.WithEventFilter(excludeNamespaces(r.ExcludedNamespaces))
+	}
+
+	if r.Sharder != nil {
+		builder = builder.WithEventFilter(r.Sharder.Predicate()).
+			Watches(r.Sharder.Watch(&autoscalingv1.ScheduledPodAutoscalerList{}), &handler.EnqueueRequestForObject{})
+	}
+
+	if r.Scheduler != nil {
+		if err := mgr.Add(r.Scheduler); err != nil {
+			return err
+		}
+
+		builder = builder.Watches(r.Scheduler.Source(), &handler.EnqueueRequestForObject{})
+	}
+
+	return builder.Complete(r)
 }
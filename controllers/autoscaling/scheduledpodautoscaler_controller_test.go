@@ -7,12 +7,18 @@ import (
 
 	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
 	"github.com/d-kuro/scheduled-pod-autoscaler/controllers/autoscaling/internal/testutil"
+	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
+	prometheustestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = ginkgo.Describe("ScheduledPodAutoscaler controller", func() {
@@ -248,6 +254,673 @@ var _ = ginkgo.Describe("ScheduledPodAutoscaler controller", func() {
 				return nil
 			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
 		})
+		ginkgo.It("should hold scheduled replicas during minimum hold duration", func() {
+			const (
+				name                              = "scheduled-scaling-hold-test"
+				scheduledPodAutoscalerMinReplicas = 1
+				scheduledPodAutoscalerMaxReplicas = 3
+				scheduleMinReplicas               = 5
+				scheduleMaxReplicas               = 10
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name,
+				WithScheduledPodAutoscalerMinReplicas(scheduledPodAutoscalerMinReplicas),
+				WithScheduledPodAutoscalerMaxReplicas(scheduledPodAutoscalerMaxReplicas),
+				WithScheduledPodAutoscalerMinimumHoldDuration(time.Hour))
+
+			// Target scheduled scaling with start at the current time and end in one hour
+			start := now.Format("15:04")
+			end := now.Add(time.Hour * 1).Format("15:04")
+			schedule := newSchedule(name,
+				WithScheduleMinReplicas(scheduleMinReplicas),
+				WithScheduleMaxReplicas(scheduleMaxReplicas),
+				WithScheduleType(autoscalingv1.Daily),
+				WithScheduleStartTime(start),
+				WithScheduleEndTime(end))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				if createdSPA.Status.LastScaleTime == nil {
+					return fmt.Errorf("lastScaleTime not found")
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			// The schedule ends, but the scheduled replicas must be held.
+			var createdSchedule autoscalingv1.Schedule
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSchedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			createdSchedule.Spec.Suspend = true
+			err = k8sClient.Update(ctx, &createdSchedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			gomega.Consistently(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if createdHPA.Spec.MinReplicas == nil || *createdHPA.Spec.MinReplicas != int32(scheduleMinReplicas) {
+					return fmt.Errorf("created HPA minReplicas is not held: want: %d, got: %v",
+						scheduleMinReplicas, createdHPA.Spec.MinReplicas)
+				}
+
+				if createdHPA.Spec.MaxReplicas != int32(scheduleMaxReplicas) {
+					return fmt.Errorf("created HPA maxReplicas is not held: want: %d, got: %d",
+						scheduleMaxReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				return nil
+			}, /*duration*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should pin replicas with freeze mode scheduled scaling", func() {
+			const (
+				name            = "scheduled-scaling-freeze-test"
+				currentReplicas = 4
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name)
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			// Emulate the HPA controller observing the current replicas of the scale target
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				createdHPA.Status.CurrentReplicas = currentReplicas
+
+				return k8sClient.Status().Update(ctx, &createdHPA)
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			// Target scheduled scaling with start at the current time and end in one hour
+			start := now.Format("15:04")
+			end := now.Add(time.Hour * 1).Format("15:04")
+			schedule := newSchedule(name,
+				WithScheduleMode(autoscalingv1.Freeze),
+				WithScheduleType(autoscalingv1.Daily),
+				WithScheduleStartTime(start),
+				WithScheduleEndTime(end))
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSchedule autoscalingv1.Schedule
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if createdHPA.Spec.MinReplicas == nil || *createdHPA.Spec.MinReplicas != currentReplicas {
+					return fmt.Errorf("created HPA minReplicas mismatch: want: %d, got: %v",
+						currentReplicas, createdHPA.Spec.MinReplicas)
+				}
+
+				if createdHPA.Spec.MaxReplicas != currentReplicas {
+					return fmt.Errorf("created HPA maxReplicas mismatch: want: %d, got: %d",
+						currentReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSchedule); err != nil {
+					return err
+				}
+
+				if createdSchedule.Status.SnapshotReplicas == nil || *createdSchedule.Status.SnapshotReplicas != currentReplicas {
+					return fmt.Errorf("schedule snapshot mismatch: want: %d, got: %v",
+						currentReplicas, createdSchedule.Status.SnapshotReplicas)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should report effective replicas and active schedules in status", func() {
+			const (
+				name                = "scheduled-scaling-status-test"
+				scheduleMinReplicas = 5
+				scheduleMaxReplicas = 10
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name)
+
+			// Target scheduled scaling with start at the current time and end in one hour
+			start := now.Format("15:04")
+			end := now.Add(time.Hour * 1).Format("15:04")
+			schedule := newSchedule(name,
+				WithScheduleMinReplicas(scheduleMinReplicas),
+				WithScheduleMaxReplicas(scheduleMaxReplicas),
+				WithScheduleType(autoscalingv1.Daily),
+				WithScheduleStartTime(start),
+				WithScheduleEndTime(end))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				want := autoscalingv1.ScheduledPodAutoscalerStatus{
+					ObservedGeneration: createdSPA.Generation,
+					CurrentMinReplicas: testutil.ToPointerInt32(scheduleMinReplicas),
+					CurrentMaxReplicas: scheduleMaxReplicas,
+					ActiveSchedules:    []string{name},
+					EffectiveSchedule:  name,
+				}
+				got := autoscalingv1.ScheduledPodAutoscalerStatus{
+					ObservedGeneration: createdSPA.Status.ObservedGeneration,
+					CurrentMinReplicas: createdSPA.Status.CurrentMinReplicas,
+					CurrentMaxReplicas: createdSPA.Status.CurrentMaxReplicas,
+					ActiveSchedules:    createdSPA.Status.ActiveSchedules,
+					EffectiveSchedule:  createdSPA.Status.EffectiveSchedule,
+				}
+
+				if diff := cmp.Diff(want, got); diff != "" {
+					return fmt.Errorf("ScheduledPodAutoscaler status mismatch (-want +got):\n%s", diff)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should adopt pre-existing HPA with adopt policy", func() {
+			const (
+				name = "adopt-hpa-test"
+			)
+
+			ctx := context.Background()
+			spa := newScheduledPodAutoscaler(name,
+				WithScheduledPodAutoscalerAdoptionPolicy(autoscalingv1.AdoptionPolicyAdopt))
+			hpa := newPreExistingHPA(name)
+
+			err := k8sClient.Create(ctx, hpa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var adoptedHPA hpav2beta2.HorizontalPodAutoscaler
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &adoptedHPA); err != nil {
+					return err
+				}
+
+				if !metav1.IsControlledBy(&adoptedHPA, &createdSPA) {
+					return fmt.Errorf("HPA is not controlled by ScheduledPodAutoscaler")
+				}
+
+				if _, found := adoptedHPA.Annotations[autoscalingv1.AnnotationOriginalHPASpec]; !found {
+					return fmt.Errorf("original spec annotation not found")
+				}
+
+				if adoptedHPA.Spec.MaxReplicas != defaultSPAMaxReplicas {
+					return fmt.Errorf("adopted HPA maxReplicas mismatch: want: %d, got: %d",
+						defaultSPAMaxReplicas, adoptedHPA.Spec.MaxReplicas)
+				}
+
+				if createdSPA.Status.HPAAdoption == nil || createdSPA.Status.HPAAdoption.Result != autoscalingv1.HPAAdopted {
+					return fmt.Errorf("adoption result mismatch: want: %s, got: %v",
+						autoscalingv1.HPAAdopted, createdSPA.Status.HPAAdoption)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should not touch pre-existing HPA with fail policy", func() {
+			const (
+				name = "reject-hpa-test"
+			)
+
+			ctx := context.Background()
+			spa := newScheduledPodAutoscaler(name)
+			hpa := newPreExistingHPA(name)
+
+			err := k8sClient.Create(ctx, hpa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				if createdSPA.Status.Condition != autoscalingv1.ScheduledPodAutoscalerDegraded {
+					return fmt.Errorf("condition mismatch: want: %s, got: %s",
+						autoscalingv1.ScheduledPodAutoscalerDegraded, createdSPA.Status.Condition)
+				}
+
+				if createdSPA.Status.HPAAdoption == nil || createdSPA.Status.HPAAdoption.Result != autoscalingv1.HPARejected {
+					return fmt.Errorf("adoption result mismatch: want: %s, got: %v",
+						autoscalingv1.HPARejected, createdSPA.Status.HPAAdoption)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			var existingHPA hpav2beta2.HorizontalPodAutoscaler
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &existingHPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+			gomega.Expect(metav1.GetControllerOf(&existingHPA)).Should(gomega.BeNil())
+			gomega.Expect(existingHPA.Spec.MaxReplicas).Should(gomega.Equal(hpa.Spec.MaxReplicas))
+		})
+		ginkgo.It("should create HPA with name override and propagated metadata", func() {
+			const (
+				name    = "hpa-metadata-test"
+				hpaName = "hpa-metadata-test-override"
+			)
+
+			ctx := context.Background()
+			spa := newScheduledPodAutoscaler(name, WithScheduledPodAutoscalerHPAMetadata(hpaName,
+				map[string]string{"team": "web"},
+				map[string]string{"argocd.argoproj.io/compare-options": "IgnoreExtraneous"}))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: hpaName, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if createdHPA.Labels["team"] != "web" {
+					return fmt.Errorf("created HPA labels mismatch: %v", createdHPA.Labels)
+				}
+
+				if createdHPA.Annotations["argocd.argoproj.io/compare-options"] != "IgnoreExtraneous" {
+					return fmt.Errorf("created HPA annotations mismatch: %v", createdHPA.Annotations)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			createdSPA.Spec.HPALabels = nil
+			err = k8sClient.Update(ctx, &createdSPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: hpaName, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if _, found := createdHPA.Labels["team"]; found {
+					return fmt.Errorf("removed label remains: %v", createdHPA.Labels)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should orphan HPA at baseline on deletion with OrphanAtBaseline policy", func() {
+			const (
+				name                = "orphan-hpa-test"
+				scheduleMinReplicas = 5
+				scheduleMaxReplicas = 10
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name,
+				WithScheduledPodAutoscalerDeletionPolicy(autoscalingv1.DeletionPolicyOrphanAtBaseline))
+			schedule := newSchedule(name,
+				WithScheduleMinReplicas(scheduleMinReplicas),
+				WithScheduleMaxReplicas(scheduleMaxReplicas),
+				WithScheduleType(autoscalingv1.Daily),
+				WithScheduleStartTime(now.Format("15:04")),
+				WithScheduleEndTime(now.Add(time.Hour*1).Format("15:04")))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				if len(createdSPA.Finalizers) == 0 {
+					return fmt.Errorf("finalizer not found")
+				}
+
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if createdHPA.Spec.MaxReplicas != scheduleMaxReplicas {
+					return fmt.Errorf("created HPA maxReplicas mismatch: want: %d, got: %d",
+						scheduleMaxReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			err = k8sClient.Delete(ctx, &createdSPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if metav1.GetControllerOf(&createdHPA) != nil {
+					return fmt.Errorf("HPA is still controlled")
+				}
+
+				if createdHPA.Spec.MaxReplicas != defaultSPAMaxReplicas {
+					return fmt.Errorf("orphaned HPA maxReplicas mismatch: want: %d, got: %d",
+						defaultSPAMaxReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should revert manually edited HPA with revert drift policy", func() {
+			const (
+				name              = "revert-drift-test"
+				driftedMaxReplica = 20
+			)
+
+			ctx := context.Background()
+			spa := newScheduledPodAutoscaler(name)
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			gomega.Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA)
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			createdHPA.Spec.MaxReplicas = driftedMaxReplica
+			err = k8sClient.Update(ctx, &createdHPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if createdHPA.Spec.MaxReplicas != defaultSPAMaxReplicas {
+					return fmt.Errorf("drifted HPA maxReplicas mismatch: want: %d, got: %d",
+						defaultSPAMaxReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+		ginkgo.It("should keep manually edited HPA with pause drift policy", func() {
+			const (
+				name              = "pause-drift-test"
+				driftedMaxReplica = 20
+			)
+
+			ctx := context.Background()
+			spa := newScheduledPodAutoscaler(name,
+				WithScheduledPodAutoscalerDriftPolicy(autoscalingv1.DriftPolicyPause))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			gomega.Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA)
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			createdHPA.Spec.MaxReplicas = driftedMaxReplica
+			err = k8sClient.Update(ctx, &createdHPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				drifted := meta.FindStatusCondition(createdSPA.Status.Conditions, autoscalingv1.ConditionDrifted)
+				if drifted == nil || drifted.Reason != autoscalingv1.ReasonDriftPaused {
+					return fmt.Errorf("drifted condition mismatch: want: %s, got: %v",
+						autoscalingv1.ReasonDriftPaused, drifted)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+			gomega.Expect(createdHPA.Spec.MaxReplicas).Should(gomega.Equal(int32(driftedMaxReplica)))
+		})
+		ginkgo.It("should record replicas without creating HPA in dry-run mode", func() {
+			const (
+				name                = "dry-run-test"
+				scheduleMinReplicas = 5
+				scheduleMaxReplicas = 10
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name)
+			spa.Annotations = map[string]string{autoscalingv1.AnnotationDryRun: "true"}
+
+			schedule := newSchedule(name,
+				WithScheduleMinReplicas(scheduleMinReplicas),
+				WithScheduleMaxReplicas(scheduleMaxReplicas),
+				WithScheduleType(autoscalingv1.Daily),
+				WithScheduleStartTime(now.Format("15:04")),
+				WithScheduleEndTime(now.Add(time.Hour*1).Format("15:04")))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				dryRun := createdSPA.Status.DryRun
+				if dryRun == nil || dryRun.MinReplicas == nil ||
+					*dryRun.MinReplicas != scheduleMinReplicas || dryRun.MaxReplicas != scheduleMaxReplicas {
+					return fmt.Errorf("dry-run status mismatch: want: %d/%d, got: %v",
+						scheduleMinReplicas, scheduleMaxReplicas, dryRun)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			var hpa hpav2beta2.HorizontalPodAutoscaler
+			err = k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &hpa)
+			gomega.Expect(apierrors.IsNotFound(err)).Should(gomega.BeTrue())
+		})
+		ginkgo.It("should orphan HPA without restoring baseline on deletion in dry-run mode", func() {
+			const (
+				name                = "dry-run-deletion-test"
+				scheduleMinReplicas = 5
+				scheduleMaxReplicas = 10
+			)
+
+			ctx := context.Background()
+			now := time.Now().UTC()
+			spa := newScheduledPodAutoscaler(name,
+				WithScheduledPodAutoscalerDeletionPolicy(autoscalingv1.DeletionPolicyOrphanAtBaseline))
+			schedule := newSchedule(name,
+				WithScheduleMinReplicas(scheduleMinReplicas),
+				WithScheduleMaxReplicas(scheduleMaxReplicas),
+				WithScheduleType(autoscalingv1.Daily),
+				WithScheduleStartTime(now.Format("15:04")),
+				WithScheduleEndTime(now.Add(time.Hour*1).Format("15:04")))
+
+			err := k8sClient.Create(ctx, spa)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			err = k8sClient.Create(ctx, schedule)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			var createdHPA hpav2beta2.HorizontalPodAutoscaler
+			var createdSPA autoscalingv1.ScheduledPodAutoscaler
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				if len(createdSPA.Finalizers) == 0 {
+					return fmt.Errorf("finalizer not found")
+				}
+
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if createdHPA.Spec.MaxReplicas != scheduleMaxReplicas {
+					return fmt.Errorf("created HPA maxReplicas mismatch: want: %d, got: %d",
+						scheduleMaxReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA); err != nil {
+					return err
+				}
+
+				createdSPA.Annotations = map[string]string{autoscalingv1.AnnotationDryRun: "true"}
+
+				return k8sClient.Update(ctx, &createdSPA)
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+
+			err = k8sClient.Delete(ctx, &createdSPA)
+			gomega.Expect(err).Should(gomega.Succeed())
+
+			gomega.Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdSPA)
+
+				return apierrors.IsNotFound(err)
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.BeTrue())
+
+			// the HPA survives the deletion, since it is no longer owned by the ScheduledPodAutoscaler.
+			gomega.Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: defaultTestNamespace}, &createdHPA); err != nil {
+					return err
+				}
+
+				if len(createdHPA.OwnerReferences) != 0 {
+					return fmt.Errorf("HPA still has owner references: %v", createdHPA.OwnerReferences)
+				}
+
+				if createdHPA.Spec.MaxReplicas != scheduleMaxReplicas {
+					return fmt.Errorf("HPA maxReplicas restored in dry-run mode: want: %d, got: %d",
+						scheduleMaxReplicas, createdHPA.Spec.MaxReplicas)
+				}
+
+				return nil
+			}, /*timeout*/ defaultTestTimeout /*pollingInterval*/, defaultTestPollingInterval).Should(gomega.Succeed())
+		})
+	})
+})
+
+var _ = ginkgo.Describe("calculateHPAReplica", func() {
+	ginkgo.It("should use the maximum replicas of the schedules", func() {
+		schedules := []autoscalingv1.Schedule{
+			*newSchedule("scale-a", WithScheduleMinReplicas(2), WithScheduleMaxReplicas(8)),
+			*newSchedule("scale-b", WithScheduleMinReplicas(5), WithScheduleMaxReplicas(6)),
+		}
+
+		min, max, effectiveSchedule := calculateHPAReplica(schedules)
+		gomega.Expect(*min).To(gomega.Equal(int32(5)))
+		gomega.Expect(*max).To(gomega.Equal(int32(8)))
+		gomega.Expect(effectiveSchedule).To(gomega.Equal("scale-b"))
+	})
+
+	ginkgo.It("should give precedence to the freeze schedule over the overlapping scale schedules", func() {
+		freeze := newSchedule("freeze", WithScheduleMode(autoscalingv1.Freeze))
+		snapshot := int32(4)
+		freeze.Status.SnapshotReplicas = &snapshot
+
+		schedules := []autoscalingv1.Schedule{
+			*newSchedule("scale", WithScheduleMinReplicas(10), WithScheduleMaxReplicas(20)),
+			*freeze,
+		}
+
+		min, max, effectiveSchedule := calculateHPAReplica(schedules)
+		gomega.Expect(*min).To(gomega.Equal(snapshot))
+		gomega.Expect(*max).To(gomega.Equal(snapshot))
+		gomega.Expect(effectiveSchedule).To(gomega.Equal("freeze"))
+	})
+})
+
+var _ = ginkgo.Describe("updateHPA", func() {
+	ginkgo.It("should label the replicas metrics with the ScheduledPodAutoscaler", func() {
+		const (
+			name    = "update-hpa-metrics-test"
+			hpaName = "adopted-hpa"
+		)
+
+		ctx := context.Background()
+		testScheme := runtime.NewScheme()
+		gomega.Expect(hpav2beta2.AddToScheme(testScheme)).To(gomega.Succeed())
+
+		spa := newScheduledPodAutoscaler(name)
+		hpa := &hpav2beta2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: hpaName, Namespace: defaultTestNamespace},
+			Spec:       spa.Spec.HorizontalPodAutoscalerSpec,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(hpa).Build()
+		gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(hpa), hpa)).To(gomega.Succeed())
+		hpa.Spec.MinReplicas = testutil.ToPointerInt32(5)
+		hpa.Spec.MaxReplicas = 10
+
+		r := &ScheduledPodAutoscalerReconciler{Client: c, Scheme: testScheme}
+		updated, err := r.updateHPA(ctx, logr.Discard(), spa, *hpa)
+		gomega.Expect(err).To(gomega.Succeed())
+		gomega.Expect(updated).To(gomega.BeTrue())
+
+		gomega.Expect(prometheustestutil.ToFloat64(minReplicasCounter.WithLabelValues(name, defaultTestNamespace))).
+			To(gomega.Equal(float64(5)))
+		gomega.Expect(prometheustestutil.ToFloat64(maxReplicasCounter.WithLabelValues(name, defaultTestNamespace))).
+			To(gomega.Equal(float64(10)))
 	})
 })
 
@@ -309,3 +982,54 @@ func WithScheduledPodAutoscalerMaxReplicas(value int) func(*autoscalingv1.Schedu
 		spa.Spec.HorizontalPodAutoscalerSpec.MaxReplicas = int32(value)
 	}
 }
+
+func WithScheduledPodAutoscalerMinimumHoldDuration(d time.Duration) func(*autoscalingv1.ScheduledPodAutoscaler) {
+	return func(spa *autoscalingv1.ScheduledPodAutoscaler) {
+		spa.Spec.MinimumHoldDuration = &metav1.Duration{Duration: d}
+	}
+}
+
+func WithScheduledPodAutoscalerAdoptionPolicy(policy autoscalingv1.AdoptionPolicy) func(*autoscalingv1.ScheduledPodAutoscaler) {
+	return func(spa *autoscalingv1.ScheduledPodAutoscaler) {
+		spa.Spec.AdoptionPolicy = policy
+	}
+}
+
+func newPreExistingHPA(name string) *hpav2beta2.HorizontalPodAutoscaler {
+	return &hpav2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultTestNamespace,
+		},
+		Spec: hpav2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: hpav2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       name,
+			},
+			MinReplicas: testutil.ToPointerInt32(2),
+			MaxReplicas: 8,
+		},
+	}
+}
+
+func WithScheduledPodAutoscalerHPAMetadata(hpaName string, labels map[string]string,
+	annotations map[string]string) func(*autoscalingv1.ScheduledPodAutoscaler) {
+	return func(spa *autoscalingv1.ScheduledPodAutoscaler) {
+		spa.Spec.HPAName = hpaName
+		spa.Spec.HPALabels = labels
+		spa.Spec.HPAAnnotations = annotations
+	}
+}
+
+func WithScheduledPodAutoscalerDeletionPolicy(policy autoscalingv1.DeletionPolicy) func(*autoscalingv1.ScheduledPodAutoscaler) {
+	return func(spa *autoscalingv1.ScheduledPodAutoscaler) {
+		spa.Spec.DeletionPolicy = policy
+	}
+}
+
+func WithScheduledPodAutoscalerDriftPolicy(policy autoscalingv1.DriftPolicy) func(*autoscalingv1.ScheduledPodAutoscaler) {
+	return func(spa *autoscalingv1.ScheduledPodAutoscaler) {
+		spa.Spec.DriftPolicy = policy
+	}
+}
@@ -0,0 +1,361 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// shardGroupLabel is the label of the Leases of the replicas that share the namespaces.
+const shardGroupLabel = "autoscaling.d-kuro.github.io/shard-group"
+
+// DefaultShardLeaseDuration is the default duration of the Lease of a replica.
+const DefaultShardLeaseDuration = 15 * time.Second
+
+// shardReleaseTimeout is the timeout to delete the Lease of the replica on shutdown.
+const shardReleaseTimeout = 5 * time.Second
+
+// Sharder splits the namespaces among the active replicas of the controller without the leader election.
+// Each replica renews its own Lease, and owns the deterministic range of the hash of the namespaces
+// determined by its position among the replicas with the live Leases.
+// The ranges are rebalanced when the replicas join or leave, and the newly owned resources are enqueued.
+// During the rebalance, two replicas may reconcile the same resource for a moment,
+// which is safe since the writes are guarded by the optimistic concurrency.
+type Sharder struct {
+	// Client writes the Leases.
+	Client client.Client
+	// APIReader reads the Leases without the cache, so that the Leases are not watched cluster-wide.
+	APIReader client.Reader
+	Log       logr.Logger
+	// Namespace is the namespace of the Leases.
+	Namespace string
+	// Group is the name shared by the replicas that split the namespaces.
+	Group string
+	// Identity is the unique name of the replica.
+	Identity string
+	// LeaseDuration is the duration after which the Lease of a replica that is not renewed is ignored.
+	// It defaults to DefaultShardLeaseDuration.
+	LeaseDuration time.Duration
+
+	mu        sync.RWMutex
+	members   []string
+	lastRenew time.Time
+	watches   []shardWatch
+
+	syncedOnce sync.Once
+	syncedChan chan struct{}
+	closeOnce  sync.Once
+}
+
+// shardWatch is the resources enqueued when the replica begins to own their namespaces.
+type shardWatch struct {
+	list   client.ObjectList
+	events chan event.GenericEvent
+}
+
+// Watch returns the source of the events of the resources of the list type in the namespaces
+// that the replica begins to own. It must be called before the Sharder is started.
+func (s *Sharder) Watch(list client.ObjectList) source.Source {
+	events := make(chan event.GenericEvent, transitionEventBufferSize)
+	s.watches = append(s.watches, shardWatch{list: list, events: events})
+
+	return &source.Channel{Source: events}
+}
+
+// Predicate returns the predicate that filters out the events of the resources in the namespaces
+// not owned by the replica.
+func (s *Sharder) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return s.Owns(obj.GetNamespace())
+	})
+}
+
+// Owns returns true if the namespace belongs to the range of the replica.
+// The replica owns no namespace until its Lease is renewed.
+func (s *Sharder) Owns(namespace string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.owns(namespace, s.members, s.lastRenew, time.Now())
+}
+
+// owns returns true if the namespace belongs to the range of the replica among the members,
+// unless the Lease of the replica has expired.
+func (s *Sharder) owns(namespace string, members []string, lastRenew time.Time, now time.Time) bool {
+	if len(members) == 0 || now.Sub(lastRenew) > s.leaseDuration() {
+		return false
+	}
+
+	return members[shardIndex(namespace, len(members))] == s.Identity
+}
+
+// IsFirstMember waits for the first renewal of the Lease of the replica, and returns true
+// if the replica is the first of the replicas with the live Leases.
+// It elects the replica that runs the tasks that must run once among the replicas without the leader election.
+// The replicas starting at the same time may both be elected, so the tasks must be safe to run concurrently.
+// It returns false if the context is done first.
+func (s *Sharder) IsFirstMember(ctx context.Context) bool {
+	select {
+	case <-s.synced():
+	case <-ctx.Done():
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.members) > 0 && s.members[0] == s.Identity && time.Since(s.lastRenew) <= s.leaseDuration()
+}
+
+// synced returns the channel closed on the first renewal of the Lease of the replica.
+func (s *Sharder) synced() chan struct{} {
+	s.syncedOnce.Do(func() {
+		s.syncedChan = make(chan struct{})
+	})
+
+	return s.syncedChan
+}
+
+// Start renews the Lease of the replica and rebalances the namespaces until the context is done.
+func (s *Sharder) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.leaseDuration() / 3)
+	defer ticker.Stop()
+
+	for {
+		s.sync(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			s.release()
+
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface,
+// since every replica owns its part of the namespaces.
+func (s *Sharder) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Sharder) leaseDuration() time.Duration {
+	if s.LeaseDuration <= 0 {
+		return DefaultShardLeaseDuration
+	}
+
+	return s.LeaseDuration
+}
+
+func (s *Sharder) leaseName() string {
+	return s.Group + "-" + s.Identity
+}
+
+// sync renews the Lease of the replica, and enqueues the resources in the newly owned namespaces
+// if the replicas have changed.
+func (s *Sharder) sync(ctx context.Context, now time.Time) {
+	log := s.Log.WithValues("lease", types.NamespacedName{Namespace: s.Namespace, Name: s.leaseName()})
+
+	if err := s.renew(ctx, now); err != nil {
+		log.Error(err, "unable to renew Lease")
+
+		return
+	}
+
+	var leases coordinationv1.LeaseList
+	if err := s.APIReader.List(ctx, &leases, client.InNamespace(s.Namespace),
+		client.MatchingLabels{shardGroupLabel: s.Group}); err != nil {
+		log.Error(err, "unable to list Leases")
+
+		return
+	}
+
+	members := liveShardMembers(leases.Items, s.Identity, now)
+
+	s.mu.Lock()
+	previous := s.members
+	previousRenew := s.lastRenew
+	s.members = members
+	s.lastRenew = now
+	s.mu.Unlock()
+
+	s.closeOnce.Do(func() {
+		close(s.synced())
+	})
+
+	shardMembersGauge.Set(float64(len(members)))
+
+	if equalMembers(previous, members) && now.Sub(previousRenew) <= s.leaseDuration() {
+		return
+	}
+
+	log.Info("rebalancing namespaces", "members", members)
+
+	for _, watch := range s.watches {
+		s.enqueue(ctx, log, watch, func(namespace string) bool {
+			return !s.owns(namespace, previous, previousRenew, now) && s.owns(namespace, members, now, now)
+		})
+	}
+}
+
+// enqueue sends the events of the resources in the namespaces acquired by the replica.
+func (s *Sharder) enqueue(ctx context.Context, log logr.Logger, watch shardWatch, acquired func(string) bool) {
+	list := watch.list.DeepCopyObject().(client.ObjectList)
+	if err := s.Client.List(ctx, list); err != nil {
+		log.Error(err, "unable to list resources to enqueue")
+
+		return
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		log.Error(err, "unable to extract resources to enqueue")
+
+		return
+	}
+
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok || !acquired(obj.GetNamespace()) {
+			continue
+		}
+
+		select {
+		case watch.events <- event.GenericEvent{Object: obj}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// renew creates or updates the Lease of the replica.
+func (s *Sharder) renew(ctx context.Context, now time.Time) error {
+	leaseDurationSeconds := int32(s.leaseDuration() / time.Second)
+	renewTime := metav1.NewMicroTime(now)
+
+	var lease coordinationv1.Lease
+
+	err := s.APIReader.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.leaseName()}, &lease)
+	if apierrors.IsNotFound(err) {
+		lease = coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.leaseName(),
+				Namespace: s.Namespace,
+				Labels:    map[string]string{shardGroupLabel: s.Group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.Identity,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+				AcquireTime:          &renewTime,
+				RenewTime:            &renewTime,
+			},
+		}
+
+		return s.Client.Create(ctx, &lease)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	lease.Spec.HolderIdentity = &s.Identity
+	lease.Spec.LeaseDurationSeconds = &leaseDurationSeconds
+	lease.Spec.RenewTime = &renewTime
+
+	return s.Client.Update(ctx, &lease)
+}
+
+// release deletes the Lease of the replica, so that the other replicas take over its namespaces
+// without waiting for the Lease to expire.
+func (s *Sharder) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), shardReleaseTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	s.members = nil
+	s.mu.Unlock()
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: s.leaseName(), Namespace: s.Namespace},
+	}
+
+	if err := s.Client.Delete(ctx, lease); client.IgnoreNotFound(err) != nil {
+		s.Log.Error(err, "unable to release Lease", "lease", client.ObjectKeyFromObject(lease))
+	}
+}
+
+// liveShardMembers returns the sorted identities of the replicas whose Lease has not expired,
+// including the replica itself.
+func liveShardMembers(leases []coordinationv1.Lease, identity string, now time.Time) []string {
+	members := []string{identity}
+
+	for _, lease := range leases {
+		spec := lease.Spec
+		if spec.HolderIdentity == nil || *spec.HolderIdentity == identity ||
+			spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+			continue
+		}
+
+		expiry := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)
+		if expiry.After(now) {
+			members = append(members, *spec.HolderIdentity)
+		}
+	}
+
+	sort.Strings(members)
+
+	return members
+}
+
+// shardIndex returns the index of the replica that owns the namespace,
+// dividing the 32-bit hash space of the namespaces into the contiguous ranges of the replicas.
+func shardIndex(namespace string, shards int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace))
+
+	return int(uint64(h.Sum32()) * uint64(shards) >> 32)
+}
+
+func equalMembers(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
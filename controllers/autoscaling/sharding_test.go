@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newSyncedSharder returns the Sharder of the identity synced with the live Leases of the other identities.
+func newSyncedSharder(identity string, others ...string) *Sharder {
+	objs := make([]client.Object, 0, len(others))
+	leaseDurationSeconds := int32(15)
+
+	for _, other := range others {
+		other := other
+		objs = append(objs, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "shard-" + other,
+				Namespace: defaultTestNamespace,
+				Labels:    map[string]string{shardGroupLabel: "shard"},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &other,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+				RenewTime:            &metav1.MicroTime{Time: time.Now()},
+			},
+		})
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	sharder := &Sharder{
+		Client:    c,
+		APIReader: c,
+		Log:       logr.Discard(),
+		Namespace: defaultTestNamespace,
+		Group:     "shard",
+		Identity:  identity,
+	}
+	sharder.sync(context.Background(), time.Now())
+
+	return sharder
+}
+
+var _ = ginkgo.Describe("Sharder", func() {
+	now := time.Date(2020, 11, 2, 9, 30, 0, 0, time.UTC)
+
+	newLease := func(identity string, renewTime time.Time) coordinationv1.Lease {
+		leaseDurationSeconds := int32(15)
+
+		return coordinationv1.Lease{
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &identity,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+				RenewTime:            &metav1.MicroTime{Time: renewTime},
+			},
+		}
+	}
+
+	ginkgo.It("should return the sorted replicas with the live Leases", func() {
+		leases := []coordinationv1.Lease{
+			newLease("replica-c", now.Add(-5*time.Second)),
+			newLease("replica-a", now.Add(-time.Minute)),
+			newLease("replica-b", now),
+		}
+
+		members := liveShardMembers(leases, "replica-d", now)
+		gomega.Expect(members).To(gomega.Equal([]string{"replica-b", "replica-c", "replica-d"}))
+	})
+
+	ginkgo.It("should split the namespaces among the replicas", func() {
+		members := []string{"replica-a", "replica-b", "replica-c"}
+		sharders := make([]*Sharder, 0, len(members))
+
+		for _, member := range members {
+			sharders = append(sharders, &Sharder{Log: logr.Discard(), Identity: member})
+		}
+
+		for i := 0; i < 100; i++ {
+			namespace := fmt.Sprintf("namespace-%d", i)
+			owners := 0
+
+			for _, sharder := range sharders {
+				if sharder.owns(namespace, members, now, now) {
+					owners++
+				}
+			}
+
+			gomega.Expect(owners).To(gomega.Equal(1), namespace)
+		}
+	})
+
+	ginkgo.It("should not own any namespace after the Lease has expired", func() {
+		sharder := &Sharder{Log: logr.Discard(), Identity: "replica-a"}
+		members := []string{"replica-a"}
+
+		gomega.Expect(sharder.owns(defaultTestNamespace, members, now, now)).To(gomega.BeTrue())
+		gomega.Expect(sharder.owns(defaultTestNamespace, members, now, now.Add(time.Minute))).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should elect the first of the replicas with the live Leases", func() {
+		gomega.Expect(newSyncedSharder("replica-a", "replica-b").IsFirstMember(context.Background())).To(gomega.BeTrue())
+		gomega.Expect(newSyncedSharder("replica-b", "replica-a").IsFirstMember(context.Background())).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should not elect the replica before the first renewal of its Lease", func() {
+		sharder := &Sharder{Log: logr.Discard(), Identity: "replica-a"}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		gomega.Expect(sharder.IsFirstMember(ctx)).To(gomega.BeFalse())
+	})
+})
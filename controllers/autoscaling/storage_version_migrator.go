@@ -0,0 +1,194 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var customResourceDefinitionGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// StorageVersionMigrator rewrites the stored objects of the custom resources in the storage version,
+// and removes the old versions from the stored versions of the CustomResourceDefinitions,
+// so that the old versions can be stopped serving in the future.
+type StorageVersionMigrator struct {
+	client.Client
+	Log logr.Logger
+	// CustomResourceDefinitions is the list of names of the CustomResourceDefinitions to migrate.
+	CustomResourceDefinitions []string
+	// Sharder restricts the migration to the first of the sharded replicas if it is set,
+	// since the migration does not run under the leader election with the sharding.
+	Sharder *Sharder
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update;patch
+
+// Start migrates the custom resources once. It implements manager.Runnable.
+// The failures are logged and do not stop the manager, so that the migration is retried on the next start.
+func (m *StorageVersionMigrator) Start(ctx context.Context) error {
+	if m.Sharder != nil && !m.Sharder.IsFirstMember(ctx) {
+		m.Log.Info("skip storage version migration in the replica that is not the first member")
+
+		return nil
+	}
+
+	for _, name := range m.CustomResourceDefinitions {
+		if err := m.migrate(ctx, name); err != nil {
+			m.Log.Error(err, "unable to migrate storage version", "customResourceDefinition", name)
+		}
+	}
+
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (m *StorageVersionMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+func (m *StorageVersionMigrator) migrate(ctx context.Context, name string) error {
+	log := m.Log.WithValues("customResourceDefinition", name)
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(customResourceDefinitionGVK)
+
+	if err := m.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+		log.Error(err, "unable to fetch CustomResourceDefinition")
+
+		return err
+	}
+
+	storageVersion, err := crdStorageVersion(crd)
+	if err != nil {
+		return err
+	}
+
+	storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if err != nil {
+		return err
+	}
+
+	if len(storedVersions) == 1 && storedVersions[0] == storageVersion {
+		log.V(1).Info("storage version is up to date", "storageVersion", storageVersion)
+
+		return nil
+	}
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	gvk := schema.GroupVersionKind{Group: group, Version: storageVersion, Kind: kind}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(kind + "List"))
+
+	if err := m.List(ctx, list); err != nil {
+		log.Error(err, "unable to list custom resources")
+
+		return err
+	}
+
+	failed := 0
+
+	for i := range list.Items {
+		if err := m.rewrite(ctx, &list.Items[i]); err != nil {
+			log.Error(err, "unable to rewrite custom resource",
+				"namespace", list.Items[i].GetNamespace(), "name", list.Items[i].GetName())
+			storageVersionMigrationFailureCounter.WithLabelValues(kind).Inc()
+
+			failed++
+		}
+	}
+
+	// The old versions are kept in the stored versions until all objects are rewritten,
+	// since the objects failed to be rewritten may still be stored in them.
+	if failed > 0 {
+		return fmt.Errorf("unable to rewrite %d of %d %s objects in the storage version %s",
+			failed, len(list.Items), kind, storageVersion)
+	}
+
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{storageVersion}, "status", "storedVersions"); err != nil {
+		return err
+	}
+
+	if err := m.Status().Update(ctx, crd); err != nil {
+		log.Error(err, "unable to update stored versions")
+
+		return err
+	}
+
+	log.Info("migrated storage version", "storageVersion", storageVersion,
+		"previousStoredVersions", storedVersions, "objects", len(list.Items))
+
+	return nil
+}
+
+// rewrite updates the object without changes, so that the API server stores it in the storage version.
+func (m *StorageVersionMigrator) rewrite(ctx context.Context, obj *unstructured.Unstructured) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := m.Update(ctx, obj)
+		if apierrors.IsConflict(err) {
+			if err := m.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+
+			return err
+		}
+
+		return client.IgnoreNotFound(err)
+	})
+}
+
+func crdStorageVersion(crd *unstructured.Unstructured) (string, error) {
+	versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return "", err
+	}
+
+	for _, version := range versions {
+		v, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if storage, _, _ := unstructured.NestedBool(v, "storage"); storage {
+			name, _, _ := unstructured.NestedString(v, "name")
+
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("storage version of CustomResourceDefinition %s is not found", crd.GetName())
+}
+
+func (m *StorageVersionMigrator) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(m)
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v2"
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// rewriteRecorder records the rewritten objects and fails to rewrite the objects in failNames.
+type rewriteRecorder struct {
+	client.Client
+	failNames map[string]bool
+	rewritten []string
+}
+
+func (c *rewriteRecorder) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if c.failNames[obj.GetName()] {
+		return fmt.Errorf("admission webhook denied the request")
+	}
+
+	c.rewritten = append(c.rewritten, obj.GetName())
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+var _ = ginkgo.Describe("StorageVersionMigrator", func() {
+	const crdName = "schedules.autoscaling.d-kuro.github.io"
+
+	newCRD := func() *unstructured.Unstructured {
+		crd := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": crdName},
+			"spec": map[string]interface{}{
+				"group": "autoscaling.d-kuro.github.io",
+				"names": map[string]interface{}{"kind": "Schedule"},
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1", "storage": false},
+					map[string]interface{}{"name": "v2", "storage": true},
+				},
+			},
+			"status": map[string]interface{}{
+				"storedVersions": []interface{}{"v1", "v2"},
+			},
+		}}
+		crd.SetGroupVersionKind(customResourceDefinitionGVK)
+
+		return crd
+	}
+
+	newObject := func(name string) *autoscalingv2.Schedule {
+		return &autoscalingv2.Schedule{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: defaultTestNamespace},
+		}
+	}
+
+	newMigrator := func(failNames ...string) (*StorageVersionMigrator, *rewriteRecorder) {
+		scheme := runtime.NewScheme()
+		gomega.Expect(autoscalingv2.AddToScheme(scheme)).To(gomega.Succeed())
+
+		recorder := &rewriteRecorder{
+			Client: fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(newCRD(), newObject("migrate-a"), newObject("migrate-b"), newObject("migrate-c")).Build(),
+			failNames: map[string]bool{},
+		}
+
+		for _, name := range failNames {
+			recorder.failNames[name] = true
+		}
+
+		return &StorageVersionMigrator{
+			Client:                    recorder,
+			Log:                       logr.Discard(),
+			CustomResourceDefinitions: []string{crdName},
+		}, recorder
+	}
+
+	storedVersions := func(c client.Client) []string {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(customResourceDefinitionGVK)
+		gomega.Expect(c.Get(context.Background(), types.NamespacedName{Name: crdName}, crd)).To(gomega.Succeed())
+
+		versions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		return versions
+	}
+
+	ginkgo.It("should rewrite the objects and remove the old stored versions", func() {
+		migrator, recorder := newMigrator()
+
+		gomega.Expect(migrator.Start(context.Background())).To(gomega.Succeed())
+		gomega.Expect(recorder.rewritten).To(gomega.ConsistOf("migrate-a", "migrate-b", "migrate-c"))
+		gomega.Expect(storedVersions(recorder.Client)).To(gomega.Equal([]string{"v2"}))
+	})
+
+	ginkgo.It("should not migrate in the replica that is not the first member", func() {
+		migrator, recorder := newMigrator()
+		migrator.Sharder = newSyncedSharder("replica-b", "replica-a")
+
+		gomega.Expect(migrator.Start(context.Background())).To(gomega.Succeed())
+		gomega.Expect(recorder.rewritten).To(gomega.BeEmpty())
+		gomega.Expect(storedVersions(recorder.Client)).To(gomega.Equal([]string{"v1", "v2"}))
+	})
+
+	ginkgo.It("should continue with the other objects and keep the stored versions on failures", func() {
+		migrator, recorder := newMigrator("migrate-b")
+		failures := testutil.ToFloat64(storageVersionMigrationFailureCounter.WithLabelValues("Schedule"))
+
+		gomega.Expect(migrator.Start(context.Background())).To(gomega.Succeed())
+		gomega.Expect(recorder.rewritten).To(gomega.ConsistOf("migrate-a", "migrate-c"))
+		gomega.Expect(storedVersions(recorder.Client)).To(gomega.Equal([]string{"v1", "v2"}))
+		gomega.Expect(testutil.ToFloat64(storageVersionMigrationFailureCounter.WithLabelValues("Schedule"))).
+			To(gomega.Equal(failures + 1))
+	})
+})
@@ -81,18 +81,22 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&ScheduledPodAutoscalerReconciler{
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("ScheduledPodAutoscaler"),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("scheduledpodautoscaler-controller"),
+		Client:       mgr.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("ScheduledPodAutoscaler"),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("scheduledpodautoscaler-controller"),
+		FieldManager: FieldManager,
+		APIReader:    mgr.GetAPIReader(),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&ScheduleReconciler{
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("Schedule"),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("schedule-controller"),
+		Client:       mgr.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("Schedule"),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("schedule-controller"),
+		FieldManager: FieldManager,
+		APIReader:    mgr.GetAPIReader(),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
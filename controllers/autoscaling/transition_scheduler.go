@@ -0,0 +1,246 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// transitionEventBufferSize is the size of the buffer of the events sent to the controller.
+const transitionEventBufferSize = 1024
+
+// maxTransitionWait is the upper limit of the time the scheduler sleeps without any transition.
+const maxTransitionWait = time.Hour
+
+// TransitionScheduler is the central in-memory scheduler that indexes the ScheduledPodAutoscalers
+// by the next transition of their Schedules, and enqueues only the ScheduledPodAutoscalers
+// whose transition has come to the controller through a channel source.
+// It replaces the periodic requeue of every ScheduledPodAutoscaler in large clusters.
+type TransitionScheduler struct {
+	Log logr.Logger
+	// Owns filters the ScheduledPodAutoscalers by the namespace if it is set,
+	// so that the replica tracks only the ScheduledPodAutoscalers in the namespaces owned by its shard.
+	Owns func(namespace string) bool
+
+	mu          sync.Mutex
+	queue       transitionQueue
+	transitions map[types.NamespacedName]*transition
+	wakeup      chan struct{}
+	events      chan event.GenericEvent
+}
+
+// NewTransitionScheduler returns a new TransitionScheduler.
+func NewTransitionScheduler(log logr.Logger) *TransitionScheduler {
+	return &TransitionScheduler{
+		Log:         log,
+		transitions: make(map[types.NamespacedName]*transition),
+		wakeup:      make(chan struct{}, 1),
+		events:      make(chan event.GenericEvent, transitionEventBufferSize),
+	}
+}
+
+// Schedule sets the next transition time of the ScheduledPodAutoscaler.
+// The zero time, or the namespace not owned by the replica, removes the ScheduledPodAutoscaler from the scheduler.
+func (s *TransitionScheduler) Schedule(key types.NamespacedName, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.IsZero() || !s.owns(key) {
+		s.forget(key)
+
+		return
+	}
+
+	if current, found := s.transitions[key]; found {
+		if current.time.Equal(t) {
+			return
+		}
+
+		current.time = t
+		heap.Fix(&s.queue, current.index)
+	} else {
+		current = &transition{key: key, time: t}
+		heap.Push(&s.queue, current)
+		s.transitions[key] = current
+	}
+
+	transitionQueueDepth.Set(float64(len(s.queue)))
+	s.notify()
+}
+
+// Forget removes the ScheduledPodAutoscaler from the scheduler.
+func (s *TransitionScheduler) Forget(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.forget(key)
+}
+
+func (s *TransitionScheduler) forget(key types.NamespacedName) {
+	current, found := s.transitions[key]
+	if !found {
+		return
+	}
+
+	heap.Remove(&s.queue, current.index)
+	delete(s.transitions, key)
+
+	transitionQueueDepth.Set(float64(len(s.queue)))
+	s.notify()
+}
+
+// owns returns true if the ScheduledPodAutoscaler is in the namespace owned by the replica.
+func (s *TransitionScheduler) owns(key types.NamespacedName) bool {
+	return s.Owns == nil || s.Owns(key.Namespace)
+}
+
+// notify wakes up the scheduler loop to recalculate the earliest transition.
+func (s *TransitionScheduler) notify() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Source returns the source of the events of the ScheduledPodAutoscalers whose transition has come.
+func (s *TransitionScheduler) Source() source.Source {
+	return &source.Channel{Source: s.events}
+}
+
+// Start runs the scheduler loop until the context is done.
+func (s *TransitionScheduler) Start(ctx context.Context) error {
+	timer := time.NewTimer(maxTransitionWait)
+	defer timer.Stop()
+
+	for {
+		due, next := s.popDue(time.Now())
+
+		for _, key := range due {
+			// the namespace may have moved to another replica after the transition was scheduled.
+			if !s.owns(key) {
+				continue
+			}
+
+			spa := &autoscalingv1.ScheduledPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			}
+
+			select {
+			case s.events <- event.GenericEvent{Object: spa}:
+				transitionEnqueuedCounter.Inc()
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		wait := maxTransitionWait
+		if !next.IsZero() && time.Until(next) < wait {
+			wait = time.Until(next)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.wakeup:
+		case <-timer.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface,
+// since the events are consumed only by the controller running in the leader.
+func (s *TransitionScheduler) NeedLeaderElection() bool {
+	return true
+}
+
+// popDue removes and returns the ScheduledPodAutoscalers whose transition is not after now,
+// and returns the earliest transition time of the remaining ones.
+func (s *TransitionScheduler) popDue(now time.Time) ([]types.NamespacedName, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []types.NamespacedName
+
+	for len(s.queue) > 0 && !s.queue[0].time.After(now) {
+		current := heap.Pop(&s.queue).(*transition)
+		delete(s.transitions, current.key)
+		due = append(due, current.key)
+	}
+
+	transitionQueueDepth.Set(float64(len(s.queue)))
+
+	if len(s.queue) == 0 {
+		return due, time.Time{}
+	}
+
+	return due, s.queue[0].time
+}
+
+// transition is the next transition time of a ScheduledPodAutoscaler.
+type transition struct {
+	key   types.NamespacedName
+	time  time.Time
+	index int
+}
+
+// transitionQueue is the min-heap of the transitions ordered by the time.
+type transitionQueue []*transition
+
+func (q transitionQueue) Len() int { return len(q) }
+
+func (q transitionQueue) Less(i, j int) bool { return q[i].time.Before(q[j].time) }
+
+func (q transitionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *transitionQueue) Push(x interface{}) {
+	t := x.(*transition)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *transitionQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return t
+}
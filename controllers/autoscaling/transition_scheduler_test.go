@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = ginkgo.Describe("TransitionScheduler", func() {
+	var (
+		scheduler *TransitionScheduler
+		cancel    context.CancelFunc
+	)
+
+	ginkgo.BeforeEach(func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+
+		scheduler = NewTransitionScheduler(logr.Discard())
+
+		go func() {
+			defer ginkgo.GinkgoRecover()
+
+			err := scheduler.Start(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		}()
+	})
+
+	ginkgo.AfterEach(func() {
+		cancel()
+	})
+
+	ginkgo.It("should enqueue the ScheduledPodAutoscalers in order of the transitions", func() {
+		now := time.Now()
+		first := types.NamespacedName{Namespace: defaultTestNamespace, Name: "first"}
+		second := types.NamespacedName{Namespace: defaultTestNamespace, Name: "second"}
+
+		scheduler.Schedule(second, now.Add(200*time.Millisecond))
+		scheduler.Schedule(first, now.Add(100*time.Millisecond))
+
+		var e event.GenericEvent
+		gomega.Eventually(scheduler.events, time.Second).Should(gomega.Receive(&e))
+		gomega.Expect(e.Object.GetName()).To(gomega.Equal(first.Name))
+		gomega.Eventually(scheduler.events, time.Second).Should(gomega.Receive(&e))
+		gomega.Expect(e.Object.GetName()).To(gomega.Equal(second.Name))
+	})
+
+	ginkgo.It("should not enqueue the forgotten ScheduledPodAutoscaler", func() {
+		key := types.NamespacedName{Namespace: defaultTestNamespace, Name: "forgotten"}
+
+		scheduler.Schedule(key, time.Now().Add(100*time.Millisecond))
+		scheduler.Forget(key)
+
+		gomega.Consistently(scheduler.events, 300*time.Millisecond).ShouldNot(gomega.Receive())
+	})
+
+	ginkgo.It("should reschedule the ScheduledPodAutoscaler to the new transition", func() {
+		key := types.NamespacedName{Namespace: defaultTestNamespace, Name: "rescheduled"}
+
+		scheduler.Schedule(key, time.Now().Add(time.Hour))
+		scheduler.Schedule(key, time.Now().Add(100*time.Millisecond))
+
+		gomega.Eventually(scheduler.events, time.Second).Should(gomega.Receive())
+	})
+})
+
+var _ = ginkgo.Describe("TransitionScheduler with Owns", func() {
+	owned := types.NamespacedName{Namespace: defaultTestNamespace, Name: "owned"}
+	notOwned := types.NamespacedName{Namespace: "other", Name: "not-owned"}
+
+	ginkgo.It("should not track the ScheduledPodAutoscalers in the namespaces not owned by the replica", func() {
+		scheduler := NewTransitionScheduler(logr.Discard())
+		scheduler.Owns = func(namespace string) bool {
+			return namespace == defaultTestNamespace
+		}
+
+		scheduler.Schedule(owned, time.Now().Add(time.Hour))
+		scheduler.Schedule(notOwned, time.Now().Add(time.Hour))
+
+		gomega.Expect(scheduler.transitions).To(gomega.HaveKey(owned))
+		gomega.Expect(scheduler.transitions).NotTo(gomega.HaveKey(notOwned))
+	})
+
+	ginkgo.It("should drop the ScheduledPodAutoscalers whose namespace has moved to another replica", func() {
+		ownedNamespace := defaultTestNamespace
+		scheduler := NewTransitionScheduler(logr.Discard())
+		scheduler.Owns = func(namespace string) bool {
+			return namespace == ownedNamespace
+		}
+
+		scheduler.Schedule(owned, time.Now().Add(100*time.Millisecond))
+
+		// the namespace moves to another replica before the scheduler is started.
+		ownedNamespace = "other"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			defer ginkgo.GinkgoRecover()
+
+			err := scheduler.Start(ctx)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		}()
+
+		gomega.Consistently(scheduler.events, 300*time.Millisecond).ShouldNot(gomega.Receive())
+	})
+})
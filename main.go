@@ -18,19 +18,33 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	autoscalingv2 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v2"
+	configv1alpha1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/config/v1alpha1"
 	autoscalingcontroller "github.com/d-kuro/scheduled-pod-autoscaler/controllers/autoscaling"
+	autoscalingwebhook "github.com/d-kuro/scheduled-pod-autoscaler/webhooks/autoscaling"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
+// inClusterNamespacePath is the path of the namespace of the pod running in a cluster.
+const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -40,37 +54,166 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
 	_ = autoscalingv1.AddToScheme(scheme)
+	_ = autoscalingv2.AddToScheme(scheme)
+	_ = configv1alpha1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	var configFile string
 	var metricsAddr string
 	var probeAddr string
 	var enableLeaderElection bool
-	syncPeriod := 1 * time.Hour
+	var leaderElectionID string
+	var leaderElectionNamespace string
+	var leaderElectionResourceLock string
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
+	var enableWebhook bool
+	var defaultTimeZone string
+	var overlapWarningWeeks int
+	var migrateStorageVersion bool
+	var enableHPAProtection bool
+	var hpaProtectionAllowedUsers string
+	var serverSideApply bool
+	var syncPeriod time.Duration
+	var maxRequeueAfter time.Duration
+	var scheduledPodAutoscalerConcurrency int
+	var scheduleConcurrency int
+	var namespaces string
+	var excludeNamespaces string
+	var enableSharding bool
+	var shardingLeaseDuration time.Duration
+	var dryRun bool
 
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 
+	flag.StringVar(&configFile, "config", "",
+		"The path to the configuration file of the controller manager. "+
+			"The command-line flags take precedence over the file.")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "probe-addr", ":9090", "The address the liveness probe and readiness probe endpoints bind to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "09d94c38.d-kuro.github.io",
+		"The name of the resource used for the leader election. "+
+			"The installations in the same namespace must have different IDs.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"The namespace of the resource used for the leader election. "+
+			"It defaults to the namespace of the controller when running in a cluster.")
+	flag.StringVar(&leaderElectionResourceLock, "leader-election-resource-lock", resourcelock.ConfigMapsLeasesResourceLock,
+		"The type of the resource used for the leader election. "+
+			"One of 'leases', 'configmapsleases', 'endpointsleases', 'configmaps' or 'endpoints'.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"The duration that the non-leader candidates wait before they try to acquire the leadership.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"The duration that the leader retries refreshing the leadership before giving it up.")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
+		"The duration the leader election clients wait between the tries of the actions.")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false,
+		"Enable admission webhooks. "+
+			"The webhook server requires a TLS certificate in /tmp/k8s-webhook-server/serving-certs.")
+	flag.StringVar(&defaultTimeZone, "default-time-zone", "UTC",
+		"The time zone set by the webhook to the Schedule whose timeZone is not specified. "+
+			"The namespace annotation "+autoscalingv1.AnnotationDefaultTimeZone+" takes precedence over it.")
+	flag.IntVar(&overlapWarningWeeks, "overlap-warning-weeks", 4,
+		"The number of weeks from now in which the webhook warns the overlaps between the schedules. "+
+			"Setting 0 disables the warnings.")
+	flag.BoolVar(&migrateStorageVersion, "migrate-storage-version", true,
+		"Rewrite the stored custom resources in the storage version and update the stored versions of the CRDs on startup. "+
+			"It takes effect only when the webhooks are enabled, since the conversion webhook is required.")
+	flag.BoolVar(&enableHPAProtection, "enable-hpa-protection", false,
+		"Enable the validating webhook that rejects the changes of the HPAs controlled by a ScheduledPodAutoscaler "+
+			"by the users other than --hpa-protection-allowed-users unless the HPA has the "+
+			autoscalingv1.AnnotationBreakGlass+"=true annotation. "+
+			"It takes effect only when the webhooks are enabled. See manifests/hpa-protection.")
+	flag.StringVar(&hpaProtectionAllowedUsers, "hpa-protection-allowed-users", "",
+		"The comma-separated list of the users allowed to change the HPAs protected by --enable-hpa-protection. "+
+			"It must contain the service account of the controller. "+
+			"Defaults to the scheduled-pod-autoscaler service account in the namespace of the controller, "+
+			"the garbage collector and the namespace controller.")
+	flag.BoolVar(&serverSideApply, "server-side-apply", true,
+		"Write the HPAs and the statuses with the server-side apply, so that the controller owns only the min/max replicas "+
+			"and its metadata after creating the HPA. "+
+			"Disable it for Kubernetes < v1.16, which does not serve the server-side apply.")
+	flag.DurationVar(&syncPeriod, "sync-period", 1*time.Hour,
+		"The minimum interval at which the watched resources are resynced.")
+	flag.DurationVar(&maxRequeueAfter, "max-requeue-after", 0,
+		"The upper limit of the time until a ScheduledPodAutoscaler is reconciled again without any events. "+
+			"If it is not set, a ScheduledPodAutoscaler is reconciled at its next transition by the transition scheduler "+
+			"and resynced every --sync-period without periodic requeues.")
+	flag.IntVar(&scheduledPodAutoscalerConcurrency, "scheduled-pod-autoscaler-concurrency", 1,
+		"The maximum number of ScheduledPodAutoscalers reconciled concurrently.")
+	flag.IntVar(&scheduleConcurrency, "schedule-concurrency", 1,
+		"The maximum number of Schedules reconciled concurrently.")
+	flag.StringVar(&namespaces, "namespaces", "",
+		"The comma-separated list of the namespaces watched by the controller. "+
+			"The cache of the controller is restricted to the namespaces. All namespaces are watched if it is empty.")
+	flag.StringVar(&excludeNamespaces, "exclude-namespaces", "",
+		"The comma-separated list of the namespaces ignored by the controller.")
+	flag.BoolVar(&enableSharding, "enable-sharding", false,
+		"Split the namespaces among the active replicas by the hash of the namespaces, "+
+			"coordinated by the Leases in --leader-election-namespace named after --leader-election-id. "+
+			"It cannot be used with --enable-leader-election.")
+	flag.DurationVar(&shardingLeaseDuration, "sharding-lease-duration", autoscalingcontroller.DefaultShardLeaseDuration,
+		"The duration after which the namespaces of a replica that does not renew its Lease are taken over by the others.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Calculate the min/max replicas and record them in the status, the events and the metrics, "+
+			"but never create, adopt or update the HPAs. The annotation "+
+			autoscalingv1.AnnotationDryRun+"=true enables it for each ScheduledPodAutoscaler.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		HealthProbeBindAddress: probeAddr,
-		LivenessEndpointName:   "/healthz",
-		Port:                   9443,
-		SyncPeriod:             &syncPeriod,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "09d94c38.d-kuro.github.io",
-	})
+	var config *configv1alpha1.ControllerConfiguration
+	if configFile != "" {
+		var err error
+		if config, err = loadConfigFile(configFile, flag.CommandLine); err != nil {
+			setupLog.Error(err, "unable to load the configuration file", "path", configFile)
+			os.Exit(1)
+		}
+	}
+
+	if enableSharding && enableLeaderElection {
+		setupLog.Error(fmt.Errorf("--enable-sharding cannot be used with --enable-leader-election"), "invalid flags")
+		os.Exit(1)
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+
+	options := ctrl.Options{
+		Scheme:                     scheme,
+		MetricsBindAddress:         metricsAddr,
+		HealthProbeBindAddress:     probeAddr,
+		LivenessEndpointName:       "/healthz",
+		Port:                       9443,
+		SyncPeriod:                 &syncPeriod,
+		LeaderElection:             enableLeaderElection,
+		LeaderElectionID:           leaderElectionID,
+		LeaderElectionNamespace:    leaderElectionNamespace,
+		LeaderElectionResourceLock: leaderElectionResourceLock,
+		LeaseDuration:              &leaseDuration,
+		RenewDeadline:              &renewDeadline,
+		RetryPeriod:                &retryPeriod,
+	}
+
+	if config != nil {
+		applyConfigFileOptions(config, &options)
+	}
+
+	watchNamespaces := splitList(namespaces)
+	switch len(watchNamespaces) {
+	case 0:
+	case 1:
+		options.Namespace = watchNamespaces[0]
+	default:
+		options.NewCache = cache.MultiNamespacedCacheBuilder(watchNamespaces)
+	}
+
+	mgr, err := ctrl.NewManager(cfg, options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
@@ -81,26 +224,158 @@ func main() {
 		os.Exit(1)
 	}
 
+	hpaGroupVersion, err := autoscalingcontroller.DetectHPAGroupVersion(discovery.NewDiscoveryClientForConfigOrDie(cfg))
+	if err != nil {
+		setupLog.Error(err, "unable to detect HPA API version")
+		os.Exit(1)
+	}
+
+	setupLog.Info("detected HPA API version", "groupVersion", hpaGroupVersion)
+
+	// the webhooks read the resources without the cache,
+	// since the cache does not serve the namespaces not watched by the controller.
+	var webhookReader client.Reader = mgr.GetClient()
+	if len(watchNamespaces) > 0 {
+		webhookReader = mgr.GetAPIReader()
+	}
+
+	excludedNamespaces := splitList(excludeNamespaces)
+
+	var sharder *autoscalingcontroller.Sharder
+	if enableSharding {
+		if sharder, err = newSharder(mgr, leaderElectionNamespace, leaderElectionID, shardingLeaseDuration); err != nil {
+			setupLog.Error(err, "unable to create sharder")
+			os.Exit(1)
+		}
+
+		if err = mgr.Add(sharder); err != nil {
+			setupLog.Error(err, "unable to add sharder")
+			os.Exit(1)
+		}
+	}
+
+	var fieldManager string
+	if serverSideApply {
+		fieldManager = autoscalingcontroller.FieldManager
+	}
+
+	scheduler := autoscalingcontroller.NewTransitionScheduler(ctrl.Log.WithName("scheduler"))
+	if sharder != nil {
+		scheduler.Owns = sharder.Owns
+	}
+
 	if err = (&autoscalingcontroller.ScheduledPodAutoscalerReconciler{
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("ScheduledPodAutoscaler"),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("scheduledpodautoscaler-controller"),
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("ScheduledPodAutoscaler"),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("scheduledpodautoscaler-controller"),
+		HPAGroupVersion:         hpaGroupVersion,
+		Scheduler:               scheduler,
+		FieldManager:            fieldManager,
+		APIReader:               mgr.GetAPIReader(),
+		MaxRequeueAfter:         maxRequeueAfter,
+		MaxConcurrentReconciles: scheduledPodAutoscalerConcurrency,
+		ExcludedNamespaces:      excludedNamespaces,
+		Sharder:                 sharder,
+		DryRun:                  dryRun,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScheduledPodAutoscaler")
 		os.Exit(1)
 	}
 
 	if err = (&autoscalingcontroller.ScheduleReconciler{
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("Schedule"),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("schedule-controller"),
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Schedule"),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("schedule-controller"),
+		FieldManager:            fieldManager,
+		APIReader:               mgr.GetAPIReader(),
+		MaxConcurrentReconciles: scheduleConcurrency,
+		ExcludedNamespaces:      excludedNamespaces,
+		Sharder:                 sharder,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Schedule")
 		os.Exit(1)
 	}
 
+	if enableWebhook {
+		if err = (&autoscalingwebhook.ScheduleDefaulter{
+			Client:          mgr.GetAPIReader(),
+			Log:             ctrl.Log.WithName("webhooks").WithName("Schedule"),
+			DefaultTimeZone: defaultTimeZone,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Schedule")
+			os.Exit(1)
+		}
+
+		if err = (&autoscalingwebhook.ScheduledPodAutoscalerDefaulter{
+			Log: ctrl.Log.WithName("webhooks").WithName("ScheduledPodAutoscaler"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ScheduledPodAutoscaler")
+			os.Exit(1)
+		}
+
+		if err = (&autoscalingwebhook.ScheduleValidator{
+			Client:              webhookReader,
+			Log:                 ctrl.Log.WithName("webhooks").WithName("Schedule"),
+			OverlapWarningWeeks: overlapWarningWeeks,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Schedule")
+			os.Exit(1)
+		}
+
+		if err = (&autoscalingwebhook.ScheduledPodAutoscalerValidator{
+			Client: webhookReader,
+			Log:    ctrl.Log.WithName("webhooks").WithName("ScheduledPodAutoscaler"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ScheduledPodAutoscaler")
+			os.Exit(1)
+		}
+
+		if enableHPAProtection {
+			allowedUsers, err := hpaProtectionUsers(hpaProtectionAllowedUsers)
+			if err != nil {
+				setupLog.Error(err, "unable to determine the users allowed to change the protected HPAs")
+				os.Exit(1)
+			}
+
+			setupLog.Info("HPA protection enabled", "allowedUsers", allowedUsers)
+
+			if err = (&autoscalingwebhook.HorizontalPodAutoscalerValidator{
+				AllowedUsernames: allowedUsers,
+				Log:              ctrl.Log.WithName("webhooks").WithName("HorizontalPodAutoscaler"),
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "HorizontalPodAutoscaler")
+				os.Exit(1)
+			}
+		}
+
+		if err = (&autoscalingv2.Schedule{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook", "webhook", "Schedule")
+			os.Exit(1)
+		}
+
+		if err = (&autoscalingv2.ScheduledPodAutoscaler{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create conversion webhook", "webhook", "ScheduledPodAutoscaler")
+			os.Exit(1)
+		}
+
+		if migrateStorageVersion {
+			if err = (&autoscalingcontroller.StorageVersionMigrator{
+				Client: mgr.GetClient(),
+				Log:    ctrl.Log.WithName("migrator"),
+				CustomResourceDefinitions: []string{
+					"schedules." + autoscalingv2.GroupVersion.Group,
+					"scheduledpodautoscalers." + autoscalingv2.GroupVersion.Group,
+				},
+				Sharder: sharder,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create storage version migrator")
+				os.Exit(1)
+			}
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -109,3 +384,78 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits the comma-separated list, ignoring the empty elements.
+func splitList(list string) []string {
+	var elements []string
+
+	for _, element := range strings.Split(list, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+
+	return elements
+}
+
+// hpaProtectionUsers returns the users allowed to change the protected HPAs.
+// If the list is empty, the service account of the controller in the namespace of the controller
+// and the controllers of kube-controller-manager that delete the HPAs are allowed.
+func hpaProtectionUsers(list string) ([]string, error) {
+	if users := splitList(list); len(users) > 0 {
+		return users, nil
+	}
+
+	namespace, err := podNamespace()
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect the namespace of the controller, "+
+			"specify --hpa-protection-allowed-users: %w", err)
+	}
+
+	return []string{
+		"system:serviceaccount:" + namespace + ":scheduled-pod-autoscaler",
+		"system:serviceaccount:kube-system:generic-garbage-collector",
+		"system:serviceaccount:kube-system:namespace-controller",
+	}, nil
+}
+
+// podNamespace returns the namespace of the pod running the controller in a cluster.
+func podNamespace() (string, error) {
+	content, err := ioutil.ReadFile(inClusterNamespacePath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// newSharder returns the Sharder of the replica, whose identity is the host name, that is, the name of the pod.
+// The Leases are in the namespace of the controller if the namespace is not specified.
+func newSharder(mgr ctrl.Manager, namespace string, group string,
+	leaseDuration time.Duration) (*autoscalingcontroller.Sharder, error) {
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		if namespace, err = podNamespace(); err != nil {
+			return nil, fmt.Errorf("unable to detect the namespace of the controller, "+
+				"specify --leader-election-namespace: %w", err)
+		}
+	}
+
+	if errs := validation.IsValidLabelValue(group); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid --leader-election-id for sharding: %s", strings.Join(errs, ", "))
+	}
+
+	return &autoscalingcontroller.Sharder{
+		Client:        mgr.GetClient(),
+		APIReader:     mgr.GetAPIReader(),
+		Log:           ctrl.Log.WithName("sharder"),
+		Namespace:     namespace,
+		Group:         group,
+		Identity:      identity,
+		LeaseDuration: leaseDuration,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHPAProtectionUsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		expected []string
+	}{
+		{
+			name:     "single user",
+			list:     "system:serviceaccount:autoscaling:scheduled-pod-autoscaler",
+			expected: []string{"system:serviceaccount:autoscaling:scheduled-pod-autoscaler"},
+		},
+		{
+			name: "spaces and empty elements",
+			list: " system:serviceaccount:autoscaling:scheduled-pod-autoscaler, ,admin ,",
+			expected: []string{
+				"system:serviceaccount:autoscaling:scheduled-pod-autoscaler",
+				"admin",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := hpaProtectionUsers(tt.list)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expected, users); diff != "" {
+				t.Errorf("users mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
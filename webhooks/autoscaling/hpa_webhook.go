@@ -0,0 +1,120 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The webhook configuration is not generated from a marker, since the webhook is opt-in.
+// See manifests/hpa-protection.
+const hpaValidatorPath = "/validate-autoscaling-v2-horizontalpodautoscaler"
+
+// HorizontalPodAutoscalerValidator protects the HPAs controlled by a ScheduledPodAutoscaler from foreign changes.
+type HorizontalPodAutoscalerValidator struct {
+	// AllowedUsernames are the users allowed to change the protected HPAs,
+	// e.g. the service account of the controller and the garbage collector.
+	AllowedUsernames []string
+	Log              logr.Logger
+}
+
+// Handle rejects the update and the deletion of the HPA controlled by a ScheduledPodAutoscaler
+// unless it is requested by the allowed users or the HPA has the break-glass annotation.
+// The HPA is decoded only as the object metadata, so that any HPA API version is accepted.
+func (v *HorizontalPodAutoscalerValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := v.Log.WithValues("horizontalPodAutoscaler", req.Namespace+"/"+req.Name)
+
+	if req.Operation != admissionv1.Update && req.Operation != admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	for _, username := range v.AllowedUsernames {
+		if req.UserInfo.Username == username {
+			return admission.Allowed("")
+		}
+	}
+
+	var old metav1.PartialObjectMetadata
+	if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+		log.Error(err, "unable to decode HorizontalPodAutoscaler")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	owner := scheduledPodAutoscalerOf(&old)
+	if owner == nil {
+		return admission.Allowed("")
+	}
+
+	// the break-glass annotation of the new object allows the change that sets it.
+	annotations := old.Annotations
+	if req.Operation == admissionv1.Update {
+		var obj metav1.PartialObjectMetadata
+		if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+			log.Error(err, "unable to decode HorizontalPodAutoscaler")
+
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		annotations = obj.Annotations
+	}
+
+	if annotations[autoscalingv1.AnnotationBreakGlass] == "true" {
+		log.Info("allowed change of protected HPA with break-glass annotation", "user", req.UserInfo.Username)
+
+		return admission.Allowed("")
+	}
+
+	log.Info("denied change of protected HPA", "user", req.UserInfo.Username, "operation", req.Operation)
+
+	return admission.Denied(fmt.Sprintf("HorizontalPodAutoscaler %s is managed by ScheduledPodAutoscaler %s/%s. "+
+		"Change the ScheduledPodAutoscaler instead, or set the %s=true annotation to the HPA to change it manually.",
+		req.Name, req.Namespace, owner.Name, autoscalingv1.AnnotationBreakGlass))
+}
+
+func (v *HorizontalPodAutoscalerValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(hpaValidatorPath, &webhook.Admission{Handler: v})
+
+	return nil
+}
+
+// scheduledPodAutoscalerOf returns the owner reference of the ScheduledPodAutoscaler controlling the object.
+func scheduledPodAutoscalerOf(obj metav1.Object) *metav1.OwnerReference {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "ScheduledPodAutoscaler" {
+		return nil
+	}
+
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil || gv.Group != autoscalingv1.GroupVersion.Group {
+		return nil
+	}
+
+	return owner
+}
@@ -0,0 +1,134 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHorizontalPodAutoscalerValidatorHandle(t *testing.T) {
+	const (
+		controllerUsername       = "system:serviceaccount:kube-system:scheduled-pod-autoscaler"
+		garbageCollectorUsername = "system:serviceaccount:kube-system:generic-garbage-collector"
+		namespaceUsername        = "system:serviceaccount:kube-system:namespace-controller"
+	)
+
+	isController := true
+	owned := metav1.ObjectMeta{
+		Name: "test", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: autoscalingv1.GroupVersion.String(), Kind: "ScheduledPodAutoscaler",
+			Name: "test-spa", UID: "uid", Controller: &isController,
+		}},
+	}
+	breakGlass := *owned.DeepCopy()
+	breakGlass.Annotations = map[string]string{autoscalingv1.AnnotationBreakGlass: "true"}
+
+	tests := []struct {
+		name      string
+		operation admissionv1.Operation
+		username  string
+		metadata  metav1.ObjectMeta
+		expected  bool
+	}{
+		{
+			name:      "not owned",
+			operation: admissionv1.Update,
+			username:  "user",
+			metadata:  metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			expected:  true,
+		},
+		{
+			name:      "update by user",
+			operation: admissionv1.Update,
+			username:  "user",
+			metadata:  owned,
+			expected:  false,
+		},
+		{
+			name:      "delete by user",
+			operation: admissionv1.Delete,
+			username:  "user",
+			metadata:  owned,
+			expected:  false,
+		},
+		{
+			name:      "update by controller",
+			operation: admissionv1.Update,
+			username:  controllerUsername,
+			metadata:  owned,
+			expected:  true,
+		},
+		{
+			name:      "delete by controller",
+			operation: admissionv1.Delete,
+			username:  controllerUsername,
+			metadata:  owned,
+			expected:  true,
+		},
+		{
+			name:      "delete by garbage collector",
+			operation: admissionv1.Delete,
+			username:  garbageCollectorUsername,
+			metadata:  owned,
+			expected:  true,
+		},
+		{
+			name:      "delete by namespace controller",
+			operation: admissionv1.Delete,
+			username:  namespaceUsername,
+			metadata:  owned,
+			expected:  true,
+		},
+		{
+			name:      "delete by unlisted service account",
+			operation: admissionv1.Delete,
+			username:  "system:serviceaccount:kube-system:replicaset-controller",
+			metadata:  owned,
+			expected:  false,
+		},
+		{
+			name:      "update with break-glass annotation",
+			operation: admissionv1.Update,
+			username:  "user",
+			metadata:  breakGlass,
+			expected:  true,
+		},
+	}
+
+	validator := &HorizontalPodAutoscalerValidator{
+		AllowedUsernames: []string{controllerUsername, garbageCollectorUsername, namespaceUsername},
+		Log:              logr.Discard(),
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+				ObjectMeta: tt.metadata,
+				Spec:       autoscalingv2beta2.HorizontalPodAutoscalerSpec{MaxReplicas: 10},
+			}
+			oldHPA := hpa.DeepCopy()
+			oldHPA.Annotations = nil
+
+			req := newTestRequest(t, tt.operation, hpa, oldHPA)
+			if tt.operation == admissionv1.Delete {
+				req = newTestRequest(t, tt.operation, nil, oldHPA)
+			}
+
+			req.UserInfo = authenticationv1.UserInfo{Username: tt.username}
+
+			resp := validator.Handle(context.Background(), req)
+			if resp.Allowed != tt.expected {
+				t.Errorf("allowed mismatch: want: %t, got: %t, result: %v", tt.expected, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	autoscalingv2 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v2"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	scheduleValidatorPath = "/validate-autoscaling-d-kuro-github-io-v1-schedule"
+	scheduleDefaulterPath = "/mutate-autoscaling-d-kuro-github-io-v1-schedule"
+)
+
+// +kubebuilder:webhook:path=/validate-autoscaling-d-kuro-github-io-v1-schedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=autoscaling.d-kuro.github.io,resources=schedules,verbs=create;update,versions=v1,name=vschedule.autoscaling.d-kuro.github.io,admissionReviewVersions={v1,v1beta1}
+
+// ScheduleValidator validates a Schedule object.
+type ScheduleValidator struct {
+	Client client.Reader
+	Log    logr.Logger
+	// OverlapWarningWeeks is the number of weeks from now in which the overlaps
+	// with the sibling schedules are warned. The overlaps are not checked if it is 0.
+	OverlapWarningWeeks int
+	decoder             *admission.Decoder
+}
+
+// Handle rejects the Schedule that cannot be evaluated by the controller,
+// and warns the overlaps with the sibling schedules of the same ScheduledPodAutoscaler.
+func (v *ScheduleValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := v.Log.WithValues("schedule", req.Namespace+"/"+req.Name)
+
+	var schedule autoscalingv1.Schedule
+	if err := v.decoder.Decode(req, &schedule); err != nil {
+		log.Error(err, "unable to decode Schedule")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var errs field.ErrorList
+
+	if req.Operation == admissionv1.Update {
+		var old autoscalingv1.Schedule
+		if err := v.decoder.DecodeRaw(req.OldObject, &old); err != nil {
+			log.Error(err, "unable to decode old Schedule")
+
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		// The updates without changes of the spec, such as the rewrites of the storage version migration,
+		// are allowed even if the stored spec no longer passes the validation.
+		if equality.Semantic.DeepEqual(old.Spec, schedule.Spec) {
+			return admission.Allowed("")
+		}
+
+		errs = schedule.Spec.ValidateUpdate(&old.Spec, field.NewPath("spec"))
+	} else {
+		errs = schedule.Spec.Validate(field.NewPath("spec"))
+	}
+
+	recurrenceErrs, err := validateRequestedRecurrence(req, &schedule)
+	if err != nil {
+		log.Error(err, "unable to convert Schedule")
+
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if len(recurrenceErrs) > 0 {
+		log.Info("denied invalid Schedule", "errors", recurrenceErrs.ToAggregate().Error())
+
+		return invalidResponse(autoscalingv2.GroupVersion.WithKind("Schedule").GroupKind(), schedule.Name, recurrenceErrs)
+	}
+
+	if len(errs) > 0 {
+		log.Info("denied invalid Schedule", "errors", errs.ToAggregate().Error())
+
+		return invalidResponse(autoscalingv1.GroupVersion.WithKind("Schedule").GroupKind(), schedule.Name, errs)
+	}
+
+	if v.OverlapWarningWeeks <= 0 {
+		return admission.Allowed("")
+	}
+
+	var schedules autoscalingv1.ScheduleList
+	if err := v.Client.List(ctx, &schedules, client.InNamespace(req.Namespace)); err != nil {
+		log.Error(err, "unable to list schedules")
+
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	now := time.Now()
+	siblings := childSchedules(schedule.Spec.ScaleTargetRef.Name, schedules.Items)
+	warnings := schedule.OverlapWarnings(siblings, now, now.AddDate(0, 0, 7*v.OverlapWarningWeeks))
+
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// validateRequestedRecurrence validates the recurrence of the Schedule requested in v2.
+// The v2 recurrence that cannot be represented in v1, e.g. non-consecutive days of week,
+// is converted to the empty v1 fields, so it is reported with the v2 fields instead.
+func validateRequestedRecurrence(req admission.Request, schedule *autoscalingv1.Schedule) (field.ErrorList, error) {
+	if req.RequestKind == nil || req.RequestKind.Version != autoscalingv2.GroupVersion.Version {
+		return nil, nil
+	}
+
+	var hub autoscalingv2.Schedule
+	if err := schedule.ConvertTo(&hub); err != nil {
+		return nil, err
+	}
+
+	return hub.Spec.Recurrence.Validate(field.NewPath("spec", "recurrence")), nil
+}
+
+// InjectDecoder injects the decoder.
+func (v *ScheduleValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+
+	return nil
+}
+
+func (v *ScheduleValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(scheduleValidatorPath, &webhook.Admission{Handler: v})
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/mutate-autoscaling-d-kuro-github-io-v1-schedule,mutating=true,failurePolicy=fail,sideEffects=None,groups=autoscaling.d-kuro.github.io,resources=schedules,verbs=create,versions=v1,name=mschedule.autoscaling.d-kuro.github.io,admissionReviewVersions={v1,v1beta1}
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+
+// ScheduleDefaulter sets the default values to a Schedule object.
+type ScheduleDefaulter struct {
+	// Client is used to get the namespace of the Schedule.
+	// It should not be backed by the cache, so that namespaces are not watched.
+	Client client.Reader
+	Log    logr.Logger
+	// DefaultTimeZone is the time zone used when neither the Schedule nor its namespace specifies it.
+	DefaultTimeZone string
+	decoder         *admission.Decoder
+}
+
+// Handle fills the TimeZone from the namespace annotation or the controller default.
+// The defaults are applied only on creation, so that updates of the existing schedules,
+// including the rewrites of the storage version migration, are stored as they are.
+func (d *ScheduleDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := d.Log.WithValues("schedule", req.Namespace+"/"+req.Name)
+
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	var schedule autoscalingv1.Schedule
+	if err := d.decoder.Decode(req, &schedule); err != nil {
+		log.Error(err, "unable to decode Schedule")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	timeZone := d.DefaultTimeZone
+
+	if schedule.Spec.TimeZone == "" {
+		var namespace corev1.Namespace
+		if err := d.Client.Get(ctx, types.NamespacedName{Name: req.Namespace}, &namespace); err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.Error(err, "unable to fetch Namespace")
+
+				return admission.Errored(http.StatusInternalServerError, err)
+			}
+		} else if value, found := namespace.Annotations[autoscalingv1.AnnotationDefaultTimeZone]; found {
+			timeZone = value
+		}
+	}
+
+	if defaulted := schedule.SetDefaults(timeZone); len(defaulted) > 0 {
+		log.V(1).Info("applied defaults to Schedule", "fields", defaulted)
+	}
+
+	return patchResponse(req, &schedule)
+}
+
+// InjectDecoder injects the decoder.
+func (d *ScheduleDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+
+	return nil
+}
+
+func (d *ScheduleDefaulter) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(scheduleDefaulterPath, &webhook.Admission{Handler: d})
+
+	return nil
+}
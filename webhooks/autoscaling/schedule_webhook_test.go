@@ -0,0 +1,400 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	autoscalingv2 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v2"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestScheduleValidatorHandle(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     autoscalingv1.ScheduleSpec
+		expected bool
+	}{
+		{
+			name: "valid",
+			spec: autoscalingv1.ScheduleSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				ScheduleType:   autoscalingv1.Weekly, StartDayOfWeek: "Monday", EndDayOfWeek: "Friday",
+				StartTime: "10:00", EndTime: "19:00",
+			},
+			expected: true,
+		},
+		{
+			name: "weekly without start day of week",
+			spec: autoscalingv1.ScheduleSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				ScheduleType:   autoscalingv1.Weekly, EndDayOfWeek: "Friday",
+				StartTime: "10:00", EndTime: "19:00",
+			},
+			expected: false,
+		},
+		{
+			name: "invalid start time",
+			spec: autoscalingv1.ScheduleSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				ScheduleType:   autoscalingv1.Daily, StartTime: "25:00", EndTime: "19:00",
+			},
+			expected: false,
+		},
+	}
+
+	validator := &ScheduleValidator{Log: logr.Discard()}
+	if err := validator.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &autoscalingv1.Schedule{
+				TypeMeta:   metav1.TypeMeta{APIVersion: autoscalingv1.GroupVersion.String(), Kind: "Schedule"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+
+			resp := validator.Handle(context.Background(), newTestRequest(t, admissionv1.Create, schedule, nil))
+			if resp.Allowed != tt.expected {
+				t.Errorf("allowed mismatch: want: %t, got: %t, result: %v", tt.expected, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func TestScheduleValidatorHandleUpdate(t *testing.T) {
+	newSchedule := func(startTime, endTime string) *autoscalingv1.Schedule {
+		return &autoscalingv1.Schedule{
+			TypeMeta:   metav1.TypeMeta{APIVersion: autoscalingv1.GroupVersion.String(), Kind: "Schedule"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: autoscalingv1.ScheduleSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				ScheduleType:   autoscalingv1.Daily, StartTime: startTime, EndTime: endTime,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		schedule *autoscalingv1.Schedule
+		expected bool
+	}{
+		{
+			name:     "unchanged start time equals end time",
+			schedule: newSchedule("10:00", "10:00"),
+			expected: true,
+		},
+		{
+			name:     "changed start time equals end time",
+			schedule: newSchedule("11:00", "11:00"),
+			expected: false,
+		},
+		{
+			name:     "invalid time zone",
+			schedule: withTimeZone(newSchedule("10:00", "10:00"), "Invalid/TimeZone"),
+			expected: false,
+		},
+	}
+
+	validator := &ScheduleValidator{Log: logr.Discard()}
+	if err := validator.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	old := newSchedule("10:00", "10:00")
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp := validator.Handle(context.Background(), newTestRequest(t, admissionv1.Update, tt.schedule, old))
+			if resp.Allowed != tt.expected {
+				t.Errorf("allowed mismatch: want: %t, got: %t, result: %v", tt.expected, resp.Allowed, resp.Result)
+			}
+		})
+	}
+
+	t.Run("unchanged invalid spec", func(t *testing.T) {
+		// The spec stored before the webhooks were enabled, e.g. rewritten by the storage version migration.
+		invalid := withTimeZone(newSchedule("10:00", "10:00"), "Invalid/TimeZone")
+
+		resp := validator.Handle(context.Background(), newTestRequest(t, admissionv1.Update, invalid, invalid))
+		if !resp.Allowed {
+			t.Errorf("request must be allowed: %v", resp.Result)
+		}
+	})
+}
+
+func withTimeZone(schedule *autoscalingv1.Schedule, timeZone string) *autoscalingv1.Schedule {
+	schedule.Spec.TimeZone = timeZone
+
+	return schedule
+}
+
+func TestScheduleValidatorHandleV2Recurrence(t *testing.T) {
+	tests := []struct {
+		name       string
+		daysOfWeek []autoscalingv2.DayOfWeek
+		expected   bool
+	}{
+		{
+			name:       "consecutive days of week",
+			daysOfWeek: []autoscalingv2.DayOfWeek{autoscalingv2.Saturday, autoscalingv2.Sunday},
+			expected:   true,
+		},
+		{
+			name: "non-consecutive days of week",
+			daysOfWeek: []autoscalingv2.DayOfWeek{
+				autoscalingv2.Monday, autoscalingv2.Wednesday, autoscalingv2.Friday,
+			},
+			expected: false,
+		},
+	}
+
+	validator := &ScheduleValidator{Log: logr.Discard()}
+	if err := validator.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &autoscalingv2.Schedule{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: autoscalingv2.ScheduleSpec{
+					ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+					Recurrence: autoscalingv2.Recurrence{
+						Type:       autoscalingv2.Weekly,
+						DaysOfWeek: tt.daysOfWeek,
+						StartTime:  &autoscalingv2.TimeOfDay{Hour: 10},
+						EndTime:    &autoscalingv2.TimeOfDay{Hour: 19},
+					},
+				},
+			}
+
+			// The API server converts the v2 request to v1 for the webhook.
+			schedule := &autoscalingv1.Schedule{}
+			if err := schedule.ConvertFrom(hub); err != nil {
+				t.Fatal(err)
+			}
+
+			schedule.TypeMeta = metav1.TypeMeta{APIVersion: autoscalingv1.GroupVersion.String(), Kind: "Schedule"}
+
+			req := newTestRequest(t, admissionv1.Create, schedule, nil)
+			req.RequestKind = &metav1.GroupVersionKind{
+				Group:   autoscalingv2.GroupVersion.Group,
+				Version: autoscalingv2.GroupVersion.Version,
+				Kind:    "Schedule",
+			}
+
+			resp := validator.Handle(context.Background(), req)
+			if resp.Allowed != tt.expected {
+				t.Fatalf("allowed mismatch: want: %t, got: %t, result: %v", tt.expected, resp.Allowed, resp.Result)
+			}
+
+			if !resp.Allowed && !strings.Contains(resp.Result.Message, "spec.recurrence.daysOfWeek") {
+				t.Errorf("message must refer to the v2 field: %s", resp.Result.Message)
+			}
+		})
+	}
+}
+
+func TestScheduleValidatorHandleOverlapWarnings(t *testing.T) {
+	five := int32(5)
+	ten := int32(10)
+
+	sibling := &autoscalingv1.Schedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-sibling", Namespace: "default"},
+		Spec: autoscalingv1.ScheduleSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+			ScheduleType:   autoscalingv1.Daily, StartTime: "09:00", EndTime: "18:00",
+			MinReplicas: &ten,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := autoscalingv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := &ScheduleValidator{
+		Client:              fake.NewClientBuilder().WithScheme(scheme).WithObjects(sibling).Build(),
+		Log:                 logr.Discard(),
+		OverlapWarningWeeks: 4,
+	}
+	if err := validator.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := &autoscalingv1.Schedule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: autoscalingv1.GroupVersion.String(), Kind: "Schedule"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: autoscalingv1.ScheduleSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+			ScheduleType:   autoscalingv1.Daily, StartTime: "10:00", EndTime: "12:00",
+			MinReplicas: &five,
+		},
+	}
+
+	resp := validator.Handle(context.Background(), newTestRequest(t, admissionv1.Create, schedule, nil))
+	if !resp.Allowed {
+		t.Fatalf("request must be allowed: %v", resp.Result)
+	}
+
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "is shadowed by Schedule test-sibling") {
+		t.Errorf("warnings mismatch: %v", resp.Warnings)
+	}
+}
+
+func newTestDecoder(t *testing.T) *admission.Decoder {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := autoscalingv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return decoder
+}
+
+func newTestRequest(t *testing.T, operation admissionv1.Operation, obj runtime.Object, oldObj runtime.Object) admission.Request {
+	t.Helper()
+
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Namespace: "default",
+			Name:      "test",
+		},
+	}
+
+	if obj != nil {
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Object = runtime.RawExtension{Raw: raw}
+	}
+
+	if oldObj != nil {
+		raw, err := json.Marshal(oldObj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.OldObject = runtime.RawExtension{Raw: raw}
+	}
+
+	return req
+}
+
+func TestScheduleDefaulterHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace *corev1.Namespace
+		timeZone  string
+		expected  string
+	}{
+		{
+			name:     "controller default",
+			timeZone: "UTC",
+			expected: "UTC",
+		},
+		{
+			name: "namespace annotation",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{autoscalingv1.AnnotationDefaultTimeZone: "Asia/Tokyo"},
+			}},
+			timeZone: "UTC",
+			expected: "Asia/Tokyo",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tt.namespace != nil {
+				builder = builder.WithObjects(tt.namespace)
+			}
+
+			defaulter := &ScheduleDefaulter{Client: builder.Build(), Log: logr.Discard(), DefaultTimeZone: tt.timeZone}
+			if err := defaulter.InjectDecoder(newTestDecoder(t)); err != nil {
+				t.Fatal(err)
+			}
+
+			schedule := &autoscalingv1.Schedule{
+				TypeMeta:   metav1.TypeMeta{APIVersion: autoscalingv1.GroupVersion.String(), Kind: "Schedule"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: autoscalingv1.ScheduleSpec{
+					ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+					ScheduleType:   autoscalingv1.Daily, StartTime: "10:00", EndTime: "19:00",
+				},
+			}
+
+			resp := defaulter.Handle(context.Background(), newTestRequest(t, admissionv1.Create, schedule, nil))
+			if !resp.Allowed {
+				t.Fatalf("request must be allowed: %v", resp.Result)
+			}
+
+			var timeZone interface{}
+			for _, patch := range resp.Patches {
+				if patch.Path == "/spec/timeZone" {
+					timeZone = patch.Value
+				}
+			}
+
+			if timeZone != tt.expected {
+				t.Errorf("timeZone patch mismatch: want: %s, got: %v, patches: %v", tt.expected, timeZone, resp.Patches)
+			}
+		})
+	}
+}
+
+func TestScheduleDefaulterHandleUpdate(t *testing.T) {
+	defaulter := &ScheduleDefaulter{Log: logr.Discard(), DefaultTimeZone: "UTC"}
+	if err := defaulter.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := &autoscalingv1.Schedule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: autoscalingv1.GroupVersion.String(), Kind: "Schedule"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: autoscalingv1.ScheduleSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+			ScheduleType:   autoscalingv1.Daily, StartTime: "10:00", EndTime: "19:00",
+		},
+	}
+
+	resp := defaulter.Handle(context.Background(), newTestRequest(t, admissionv1.Update, schedule, schedule))
+	if !resp.Allowed {
+		t.Fatalf("request must be allowed: %v", resp.Result)
+	}
+
+	if len(resp.Patches) != 0 {
+		t.Errorf("patches must be empty on update: %v", resp.Patches)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"net/http"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	scheduledPodAutoscalerValidatorPath = "/validate-autoscaling-d-kuro-github-io-v1-scheduledpodautoscaler"
+	scheduledPodAutoscalerDefaulterPath = "/mutate-autoscaling-d-kuro-github-io-v1-scheduledpodautoscaler"
+)
+
+// +kubebuilder:webhook:path=/validate-autoscaling-d-kuro-github-io-v1-scheduledpodautoscaler,mutating=false,failurePolicy=fail,sideEffects=None,groups=autoscaling.d-kuro.github.io,resources=scheduledpodautoscalers,verbs=create;update,versions=v1,name=vscheduledpodautoscaler.autoscaling.d-kuro.github.io,admissionReviewVersions={v1,v1beta1}
+
+// ScheduledPodAutoscalerValidator validates a ScheduledPodAutoscaler object.
+type ScheduledPodAutoscalerValidator struct {
+	Client  client.Reader
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// Handle rejects the ScheduledPodAutoscaler whose HPA spec would be rejected by the HPA validation,
+// or which is not compatible with its child Schedules.
+func (v *ScheduledPodAutoscalerValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := v.Log.WithValues("scheduledPodAutoscaler", req.Namespace+"/"+req.Name)
+
+	var spa autoscalingv1.ScheduledPodAutoscaler
+	if err := v.decoder.Decode(req, &spa); err != nil {
+		log.Error(err, "unable to decode ScheduledPodAutoscaler")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	unchanged, err := isSpecUnchanged(v.decoder, req, &spa)
+	if err != nil {
+		log.Error(err, "unable to decode old ScheduledPodAutoscaler")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if unchanged {
+		return admission.Allowed("")
+	}
+
+	specPath := field.NewPath("spec")
+
+	errs := spa.Spec.Validate(specPath)
+	if len(errs) == 0 {
+		var schedules autoscalingv1.ScheduleList
+		if err := v.Client.List(ctx, &schedules, client.InNamespace(req.Namespace)); err != nil {
+			log.Error(err, "unable to list schedules")
+
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		errs = spa.Spec.ValidateSchedules(childSchedules(spa.Name, schedules.Items), specPath)
+	}
+
+	if len(errs) > 0 {
+		log.Info("denied invalid ScheduledPodAutoscaler", "errors", errs.ToAggregate().Error())
+
+		return invalidResponse(autoscalingv1.GroupVersion.WithKind("ScheduledPodAutoscaler").GroupKind(), spa.Name, errs)
+	}
+
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder.
+func (v *ScheduledPodAutoscalerValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+
+	return nil
+}
+
+func (v *ScheduledPodAutoscalerValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(scheduledPodAutoscalerValidatorPath, &webhook.Admission{Handler: v})
+
+	return nil
+}
+
+// childSchedules returns the schedules that refer to the ScheduledPodAutoscaler.
+func childSchedules(name string, schedules []autoscalingv1.Schedule) []autoscalingv1.Schedule {
+	children := make([]autoscalingv1.Schedule, 0, len(schedules))
+
+	for _, schedule := range schedules {
+		if schedule.Spec.ScaleTargetRef.Name == name {
+			children = append(children, schedule)
+		}
+	}
+
+	return children
+}
+
+// +kubebuilder:webhook:path=/mutate-autoscaling-d-kuro-github-io-v1-scheduledpodautoscaler,mutating=true,failurePolicy=fail,sideEffects=None,groups=autoscaling.d-kuro.github.io,resources=scheduledpodautoscalers,verbs=create;update,versions=v1,name=mscheduledpodautoscaler.autoscaling.d-kuro.github.io,admissionReviewVersions={v1,v1beta1}
+
+// ScheduledPodAutoscalerDefaulter sets the default values to a ScheduledPodAutoscaler object.
+type ScheduledPodAutoscalerDefaulter struct {
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// Handle fills the minReplicas and the apiVersion/kind of the scaleTargetRef of the HPA spec.
+func (d *ScheduledPodAutoscalerDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := d.Log.WithValues("scheduledPodAutoscaler", req.Namespace+"/"+req.Name)
+
+	var spa autoscalingv1.ScheduledPodAutoscaler
+	if err := d.decoder.Decode(req, &spa); err != nil {
+		log.Error(err, "unable to decode ScheduledPodAutoscaler")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	unchanged, err := isSpecUnchanged(d.decoder, req, &spa)
+	if err != nil {
+		log.Error(err, "unable to decode old ScheduledPodAutoscaler")
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if unchanged {
+		return admission.Allowed("")
+	}
+
+	if defaulted := spa.SetDefaults(); len(defaulted) > 0 {
+		log.V(1).Info("applied defaults to ScheduledPodAutoscaler", "fields", defaulted)
+	}
+
+	return patchResponse(req, &spa)
+}
+
+// InjectDecoder injects the decoder.
+func (d *ScheduledPodAutoscalerDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+
+	return nil
+}
+
+func (d *ScheduledPodAutoscalerDefaulter) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(scheduledPodAutoscalerDefaulterPath, &webhook.Admission{Handler: d})
+
+	return nil
+}
+
+// isSpecUnchanged returns true if the request updates the ScheduledPodAutoscaler without changes of the spec,
+// such as the rewrites of the storage version migration, which must be stored as they are.
+func isSpecUnchanged(decoder *admission.Decoder, req admission.Request, spa *autoscalingv1.ScheduledPodAutoscaler) (bool, error) {
+	if req.Operation != admissionv1.Update {
+		return false, nil
+	}
+
+	var old autoscalingv1.ScheduledPodAutoscaler
+	if err := decoder.DecodeRaw(req.OldObject, &old); err != nil {
+		return false, err
+	}
+
+	return equality.Semantic.DeepEqual(old.Spec, spa.Spec), nil
+}
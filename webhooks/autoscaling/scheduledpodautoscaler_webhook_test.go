@@ -0,0 +1,131 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	autoscalingv1 "github.com/d-kuro/scheduled-pod-autoscaler/apis/autoscaling/v1"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestScheduledPodAutoscalerValidatorHandle(t *testing.T) {
+	two := int32(2)
+	five := int32(5)
+
+	schedule := &autoscalingv1.Schedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "default"},
+		Spec: autoscalingv1.ScheduleSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "test"},
+			MaxReplicas:    &two,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		spec     autoscalingv2beta2.HorizontalPodAutoscalerSpec
+		expected bool
+	}{
+		{
+			name: "valid",
+			spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx"},
+				MinReplicas:    &two,
+				MaxReplicas:    10,
+			},
+			expected: true,
+		},
+		{
+			name: "missing scale target kind",
+			spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				MaxReplicas:    10,
+			},
+			expected: false,
+		},
+		{
+			name: "incompatible with child schedule",
+			spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "nginx"},
+				MinReplicas:    &five,
+				MaxReplicas:    10,
+			},
+			expected: false,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := autoscalingv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := &ScheduledPodAutoscalerValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(schedule).Build(),
+		Log:    logr.Discard(),
+	}
+	if err := validator.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			spa := &autoscalingv1.ScheduledPodAutoscaler{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: autoscalingv1.GroupVersion.String(), Kind: "ScheduledPodAutoscaler",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       autoscalingv1.ScheduledPodAutoscalerSpec{HorizontalPodAutoscalerSpec: tt.spec},
+			}
+
+			resp := validator.Handle(context.Background(), newTestRequest(t, admissionv1.Create, spa, nil))
+			if resp.Allowed != tt.expected {
+				t.Errorf("allowed mismatch: want: %t, got: %t, result: %v", tt.expected, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func TestScheduledPodAutoscalerHandleUnchangedSpec(t *testing.T) {
+	// The spec stored before the webhooks were enabled, which is invalid and not defaulted.
+	spa := &autoscalingv1.ScheduledPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: autoscalingv1.GroupVersion.String(), Kind: "ScheduledPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: autoscalingv1.ScheduledPodAutoscalerSpec{
+			HorizontalPodAutoscalerSpec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Name: "nginx"},
+				MaxReplicas:    10,
+			},
+		},
+	}
+
+	validator := &ScheduledPodAutoscalerValidator{Log: logr.Discard()}
+	if err := validator.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := validator.Handle(context.Background(), newTestRequest(t, admissionv1.Update, spa, spa))
+	if !resp.Allowed {
+		t.Errorf("request must be allowed: %v", resp.Result)
+	}
+
+	defaulter := &ScheduledPodAutoscalerDefaulter{Log: logr.Discard()}
+	if err := defaulter.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp = defaulter.Handle(context.Background(), newTestRequest(t, admissionv1.Update, spa, spa))
+	if !resp.Allowed {
+		t.Fatalf("request must be allowed: %v", resp.Result)
+	}
+
+	if len(resp.Patches) != 0 {
+		t.Errorf("patches must be empty for the unchanged spec: %v", resp.Patches)
+	}
+}